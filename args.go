@@ -0,0 +1,157 @@
+package clic
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googollee/clic/source"
+	"github.com/googollee/clic/structtags"
+)
+
+// argFields returns positional fields in `fields`, ordered by positions.
+// It returns an error if two fields have the same position, or a variadic field is not the last one.
+func argFields(fields []structtags.Field) ([]structtags.Field, error) {
+	var ret []structtags.Field
+	for _, field := range fields {
+		if field.Arg > 0 {
+			ret = append(ret, field)
+		}
+	}
+	slices.SortStableFunc(ret, func(a, b structtags.Field) int { return a.Arg - b.Arg })
+
+	for i, field := range ret {
+		if i > 0 && ret[i-1].Arg == field.Arg {
+			return nil, fmt.Errorf("field %v and field %v are bound to the same positional argument %d", ret[i-1].Name, field.Name, field.Arg)
+		}
+		if i > 0 && ret[i-1].IsVariadic() {
+			return nil, fmt.Errorf("field %v is bound to positional argument %d after variadic field %v", field.Name, field.Arg, ret[i-1].Name)
+		}
+	}
+
+	return ret, nil
+}
+
+// checkArgs returns positional fields in `fields` like [argFields], and checks that positions start from 1 without a gap,
+// and a required argument doesn't follow an optional one.
+func checkArgs(fields []structtags.Field) ([]structtags.Field, error) {
+	args, err := argFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, field := range args {
+		if field.Arg != i+1 {
+			return nil, fmt.Errorf("no field is bound to positional argument %d", i+1)
+		}
+		if i > 0 && isOptionalArg(args[i-1]) && !isOptionalArg(field) {
+			return nil, fmt.Errorf("required positional argument %s follows optional positional argument %s", argName(field), argName(args[i-1]))
+		}
+	}
+
+	return args, nil
+}
+
+// isOptionalArg returns true if the positional argument of `field` could be omitted.
+func isOptionalArg(field structtags.Field) bool {
+	if field.Required {
+		return false
+	}
+
+	return field.IsVariadic() || field.HasDefault
+}
+
+// argKey returns the key of the positional argument of `field`, like "<src>" or "<files>...".
+func argKey(field structtags.Field) string {
+	ret := "<" + field.Name[len(field.Name)-1] + ">"
+	if field.IsVariadic() {
+		ret += "..."
+	}
+
+	return ret
+}
+
+// argName returns the name of the positional argument of `field` in the usage, like "<src>", "[<dst>]" or "<files>...".
+func argName(field structtags.Field) string {
+	if isOptionalArg(field) {
+		return "[" + argKey(field) + "]"
+	}
+
+	return argKey(field)
+}
+
+// argOrigin returns the origin of a value from the positional argument of `field`.
+func argOrigin(field structtags.Field) structtags.Origin {
+	return structtags.Origin{Source: "arg", Key: argKey(field)}
+}
+
+// argsLine returns the usage line of positional arguments in `fields`, like "<src> <dst>...".
+func argsLine(fields []structtags.Field) string {
+	args, err := argFields(fields)
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(args))
+	for _, field := range args {
+		names = append(names, argName(field))
+	}
+
+	return strings.Join(names, " ")
+}
+
+// parseArgs sets positional fields with positional arguments left by flags.
+// Positional arguments are ignored if no field is bound to them.
+func (s *Set) parseArgs() ([]*source.FieldError, error) {
+	fields, err := checkArgs(s.fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	var errs []*source.FieldError
+	args := s.positional
+	for _, field := range fields {
+		if len(args) == 0 {
+			break
+		}
+
+		origin := argOrigin(field)
+		values := args[:1]
+		if field.IsVariadic() {
+			values = args
+			field.Clear()
+		}
+		args = args[len(values):]
+
+		var err error
+		for _, value := range values {
+			if err = field.AppendText([]byte(value)); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			errs = append(errs, &source.FieldError{Field: field.Name, Origin: origin, Err: err})
+			continue
+		}
+		field.SetOrigin(origin)
+	}
+
+	if len(args) > 0 {
+		return nil, fmt.Errorf("too many positional arguments %q, usage: %s", args, argsLine(fields))
+	}
+
+	return errs, nil
+}
+
+// hasArgs returns true if any field in `fields` is a positional argument.
+func hasArgs(fields []structtags.Field) bool {
+	return slices.ContainsFunc(fields, func(field structtags.Field) bool { return field.Arg > 0 })
+}
+
+// sourceFields returns fields which sources set, without positional fields.
+func sourceFields(fields []structtags.Field) []structtags.Field {
+	return slices.DeleteFunc(slices.Clone(fields), func(field structtags.Field) bool { return field.Arg > 0 })
+}
@@ -0,0 +1,274 @@
+package clic_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic"
+	"github.com/googollee/clic/source"
+	"github.com/googollee/clic/structtags"
+)
+
+func ExampleSet_positionalArgs() {
+	type Copy struct {
+		Force bool     `clic:"force,false,overwrite existing files"`
+		Dst   string   `clic:"dst,,the destination" arg:"1"`
+		Srcs  []string `clic:"srcs,,the sources" arg:"2" required:"true"`
+	}
+
+	fset := flag.NewFlagSet("cp", flag.ContinueOnError)
+	set := clic.NewSet(fset, source.Flag())
+
+	var cp Copy
+	set.RegisterValue("cp", &cp)
+
+	args := []string{"-cp.force", "true", "/tmp", "a.txt", "b.txt"}
+	if err := set.Parse(context.Background(), args); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	fmt.Printf("copy: %+v\n", cp)
+
+	// Output:
+	// copy: {Force:true Dst:/tmp Srcs:[a.txt b.txt]}
+}
+
+type argsConfig struct {
+	Count int      `clic:"count" arg:"1"`
+	Name  string   `clic:"name,anonymous" arg:"2"`
+	Rest  []string `clic:"rest" arg:"3"`
+}
+
+func TestArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    argsConfig
+		wantErr bool
+	}{
+		{"Required", []string{"1"}, argsConfig{Count: 1, Name: "anonymous"}, false},
+		{"Optional", []string{"1", "bob"}, argsConfig{Count: 1, Name: "bob"}, false},
+		{"Variadic", []string{"1", "bob", "a", "b"}, argsConfig{Count: 1, Name: "bob", Rest: []string{"a", "b"}}, false},
+		{"AfterTerminator", []string{"--", "1", "-bob"}, argsConfig{Count: 1, Name: "-bob"}, false},
+		{"Missing", nil, argsConfig{}, true},
+		{"Invalid", []string{"abc"}, argsConfig{}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError), source.Flag(), source.Env())
+
+			var cfg argsConfig
+			set.RegisterValue("args", &cfg)
+
+			err := set.Parse(t.Context(), tc.args)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("set.Parse(%q) = %v, want error: %v", tc.args, err, tc.wantErr)
+			}
+			var validationErr *clic.ValidationError
+			if tc.wantErr && !errors.As(err, &validationErr) {
+				t.Errorf("set.Parse(%q) = %v, want a %T", tc.args, err, validationErr)
+			}
+
+			if diff := cmp.Diff(cfg, tc.want); diff != "" {
+				t.Errorf("after set.Parse(%q), config diff: (-got, +want)\n%s", tc.args, diff)
+			}
+		})
+	}
+}
+
+func TestArgsEmptyDefault(t *testing.T) {
+	type Copy struct {
+		Src string `clic:"src" arg:"1"`
+		Dst string `clic:"dst" default:"" arg:"2"`
+	}
+
+	var output bytes.Buffer
+	fset := flag.NewFlagSet("cp", flag.ContinueOnError)
+	fset.SetOutput(&output)
+	set := clic.NewSet(fset, source.Flag())
+
+	var cp Copy
+	set.RegisterValue("cp", &cp)
+
+	if err := set.Parse(t.Context(), []string{"a"}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+	if diff := cmp.Diff(cp, Copy{Src: "a"}); diff != "" {
+		t.Errorf("after set.Parse(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	fset.Usage()
+	if want := "Usage: cp [flags] <src> [<dst>]\n"; output.String() != want {
+		t.Errorf("help = %q, want: %q", output.String(), want)
+	}
+}
+
+func TestArgsError(t *testing.T) {
+	type fixed struct {
+		Src string `clic:"src" arg:"1"`
+		Dst string `clic:"dst" arg:"2"`
+	}
+	type gap struct {
+		Src string `clic:"src" arg:"2"`
+	}
+	type requiredAfterOptional struct {
+		Src string `clic:"src,a" arg:"1"`
+		Dst string `clic:"dst" arg:"2"`
+	}
+
+	tests := []struct {
+		name  string
+		value any
+		args  []string
+		want  string
+	}{
+		{"TooMany", &fixed{}, []string{"a", "b", "c"}, `too many positional arguments ["c"], usage: <src> <dst>`},
+		{"Missing", &fixed{}, []string{"a"}, "1 invalid config fields:\n  c.dst (arg <dst>): required value is missing"},
+		{"Gap", &gap{}, []string{"a"}, "no field is bound to positional argument 1"},
+		{"RequiredAfterOptional", &requiredAfterOptional{}, []string{"a", "b"}, "required positional argument <dst> follows optional positional argument [<src>]"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError), source.Flag())
+			set.RegisterValue("c", tc.value)
+
+			err := set.Parse(t.Context(), tc.args)
+			if err == nil {
+				t.Fatalf("set.Parse(%q) returns no error, want an error", tc.args)
+			}
+			if got := err.Error(); got != tc.want {
+				t.Errorf("set.Parse(%q) = %q, want: %q", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArgsInvalidRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(set *clic.Set)
+	}{
+		{"SamePosition", func(set *clic.Set) {
+			set.RegisterValue("a", &struct {
+				Src string `clic:"src" arg:"1"`
+			}{})
+			set.RegisterValue("b", &struct {
+				Src string `clic:"src" arg:"1"`
+			}{})
+		}},
+		{"AfterVariadic", func(set *clic.Set) {
+			set.RegisterValue("a", &struct {
+				Srcs []string `clic:"srcs" arg:"1"`
+				Dst  string   `clic:"dst" arg:"2"`
+			}{})
+		}},
+		{"ArgsBeforeCommand", func(set *clic.Set) {
+			set.RegisterValue("a", &struct {
+				Src string `clic:"src" arg:"1"`
+			}{})
+			set.Command("cmd", "")
+		}},
+		{"ArgsAfterCommand", func(set *clic.Set) {
+			set.Command("cmd", "")
+			set.RegisterValue("a", &struct {
+				Src string `clic:"src" arg:"1"`
+			}{})
+		}},
+		{"ArgsInParentCommand", func(set *clic.Set) {
+			cmd := set.Command("cmd", "")
+			cmd.Command("sub", "")
+			cmd.RegisterValue("a", &struct {
+				Src string `clic:"src" arg:"1"`
+			}{})
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError), source.Flag())
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("registering passes, want a panic")
+				}
+			}()
+
+			tc.register(set)
+		})
+	}
+}
+
+func TestArgsWithCommand(t *testing.T) {
+	type Copy struct {
+		Src string `clic:"src" arg:"1"`
+		Dst string `clic:"dst" arg:"2"`
+	}
+
+	var output bytes.Buffer
+	fset := flag.NewFlagSet("svc", flag.ContinueOnError)
+	fset.SetOutput(&output)
+	set := clic.NewSet(fset, source.Flag(), source.Env())
+
+	var cp Copy
+	set.Command("cp", "copy a file").RegisterValue("cp", &cp)
+
+	t.Setenv("CP_SRC", "from_env")
+
+	if err := set.Parse(t.Context(), []string{"cp", "a", "b"}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+	if diff := cmp.Diff(cp, Copy{Src: "a", Dst: "b"}); diff != "" {
+		t.Errorf("after set.Parse(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	want := []clic.FieldProvenance{
+		{Name: []string{"cp", "src"}, Value: "a", Origin: structtags.Origin{Source: "arg", Key: "<src>"}},
+		{Name: []string{"cp", "dst"}, Value: "b", Origin: structtags.Origin{Source: "arg", Key: "<dst>"}},
+	}
+	if diff := cmp.Diff(set.Provenance(), want); diff != "" {
+		t.Errorf("set.Provenance() diff: (-got, +want)\n%s", diff)
+	}
+
+	if err := set.Reload(t.Context()); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+	if diff := cmp.Diff(cp, Copy{Src: "a", Dst: "b"}); diff != "" {
+		t.Errorf("after set.Reload(), config diff: (-got, +want)\n%s", diff)
+	}
+}
+
+func TestArgsHelp(t *testing.T) {
+	type Copy struct {
+		Force bool     `clic:"force,false,overwrite existing files"`
+		Src   string   `clic:"src" arg:"1"`
+		Dst   []string `clic:"dst" arg:"2" required:"true"`
+	}
+
+	var output bytes.Buffer
+	fset := flag.NewFlagSet("cp", flag.ContinueOnError)
+	fset.SetOutput(&output)
+	set := clic.NewSet(fset, source.Flag())
+
+	var cp Copy
+	set.RegisterValue("cp", &cp)
+
+	if err := set.Parse(t.Context(), []string{"-h"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("set.Parse() = %v, want: %v", err, flag.ErrHelp)
+	}
+
+	want := `Usage: cp [flags] <src> <dst>...
+  -cp.force value
+    	overwrite existing files (default false)
+`
+	if diff := cmp.Diff(output.String(), want); diff != "" {
+		t.Errorf("help diff: (-got, +want)\n%s", diff)
+	}
+}
@@ -6,15 +6,23 @@ Usage:
   - [Parse] function should be called at the beginning of "main()", before calling functions in other packages.
   - [Parse] function must not be called in `func init()`, because other sub-packages may not finish initialization at that time.
 
+Fields are declared by struct tags, like `clic:"name,default,description"`. See [github.com/googollee/clic/structtags.Field] for all tags.
+[Command] declares subcommands, and [Reload] and [Watch] reload the config while running.
+
 See examples for the usage.
 */
 package clic
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"time"
+
+	"github.com/googollee/clic/source"
 )
 
 var CommandLine = NewSet(flag.CommandLine, DefaultSources...)
@@ -22,6 +30,9 @@ var CommandLine = NewSet(flag.CommandLine, DefaultSources...)
 /*
 RegisterType registers a "Config" type with the "name" as the scope name and returns a getter function which returns a parsed Config value.
 
+The getter returns an immutable snapshot of the latest successfully parsed config, which is replaced atomically when reloading.
+With a context returned by [Pin], it returns the snapshot pinned in the context. See [RegisterSetType] for details.
+
 Example:
 
 	package main
@@ -29,24 +40,25 @@ Example:
 	func main() {
 		ctx := context.Background()
 
-		loadCfg := clic.RegisterType[database.Config]()
+		loadCfg := clic.RegisterType[database.Config]("database")
 
 		clic.Parse(ctx)
 
-		db := database.New(loadCfg())
+		db := database.New(loadCfg(ctx))
+
+		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			ctx := clic.Pin(r.Context())
+
+			handle(ctx, loadCfg(ctx)) // loadCfg(ctx) returns the same snapshot in the whole request.
+		})
 	}
 */
 func RegisterType[Config any](name string) func(ctx context.Context) *Config {
-	var c Config
-	Register(name, &c)
-
-	return func(ctx context.Context) *Config {
-		return &c
-	}
+	return RegisterSetType[Config](CommandLine, name)
 }
 
 /*
-Register registers a "Config" value with the "name" as the scope name. The value is filled after calling [Parse] function.
+Register registers a "Config" value with the "name" as the scope name. The value is filled after calling [Parse] function, and is updated in place when reloading.
 
 Example:
 
@@ -94,11 +106,231 @@ func RegisterCallback(prefix string, f any) {
 	CommandLine.RegisterCallback(prefix, f)
 }
 
+/*
+DependOn declares that the callback of the "prefix" scope is called after callbacks of the "depends" scopes. Without dependencies, callbacks are called in the order of registration.
+
+Example:
+
+	package main
+
+	func main() {
+		ctx := context.Background()
+
+		clic.RegisterCallback("database", initDatabase)
+		clic.RegisterCallback("log", initLog)
+		clic.DependOn("database", "log")
+
+		clic.Parse(ctx) // calls initLog(), then initDatabase()
+	}
+*/
+func DependOn(prefix string, depends ...string) {
+	CommandLine.DependOn(prefix, depends...)
+}
+
+/*
+OnReload registers a reload callback with the "name" as the scope name, which must be registered before. The callback is called with the old value and the new value after a reload changes the scope.
+
+Example:
+
+	package main
+
+	type Log struct {
+		Level slog.Level `clic:"level,INFO,the level of log"`
+	}
+
+	func main() {
+		ctx := context.Background()
+
+		var log Log
+		clic.Register("log", &log)
+		clic.OnReload("log", func(ctx context.Context, old, new *Log) {
+			slog.SetLogLoggerLevel(new.Level)
+		})
+
+		clic.Parse(ctx)
+
+		go clic.Watch(ctx, time.Second)
+	}
+*/
+func OnReload(prefix string, callback any) {
+	CommandLine.OnReload(prefix, callback)
+}
+
+// Watch checks config files every "interval", and reloads the config if any file changes. It blocks until "ctx" is done.
+// See [Set.Watch] for details.
+func Watch(ctx context.Context, interval time.Duration) error {
+	return CommandLine.Watch(ctx, interval)
+}
+
+// Reload parses all sources again and updates registered values. See [Set.Reload] for details.
+func Reload(ctx context.Context) error {
+	return CommandLine.Reload(ctx)
+}
+
+/*
+ReloadOnSignal reloads the config when the process receives one of "sigs", or SIGHUP if "sigs" is empty. It blocks until "ctx" is done.
+
+Example:
+
+	package main
+
+	func main() {
+		ctx := context.Background()
+
+		var log Log
+		clic.Register("log", &log)
+
+		clic.Parse(ctx)
+
+		go clic.ReloadOnSignal(ctx)
+
+		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			clic.RLock()
+			level := log.Level
+			clic.RUnlock()
+
+			fmt.Fprintln(w, level)
+		})
+	}
+*/
+func ReloadOnSignal(ctx context.Context, sigs ...os.Signal) error {
+	return CommandLine.ReloadOnSignal(ctx, sigs...)
+}
+
+// Pin returns a child context of "ctx" with current snapshots of all registered scopes. See [Set.Pin] for details.
+func Pin(ctx context.Context) context.Context {
+	return CommandLine.Pin(ctx)
+}
+
+// RLock locks registered values for reading, and a reload waits until [RUnlock] is called. See [Set.RLock] for details.
+func RLock() {
+	CommandLine.RLock()
+}
+
+// RUnlock undoes a single [RLock] call.
+func RUnlock() {
+	CommandLine.RUnlock()
+}
+
+/*
+Command returns the subcommand with the "name" of [CommandLine], and creates it with the "description" if it doesn't exist.
+Scopes and callbacks registered in a command are parsed and called only if args select the command. See [Set.Command] for details.
+
+Example:
+
+	package main
+
+	type Migrate struct {
+		Dir string `clic:"dir,./migrations,the directory of migrations"`
+	}
+
+	func main() {
+		ctx := context.Background()
+
+		var db database.Config
+		clic.Register("database", &db)
+
+		migrate := clic.Command("migrate", "run database migrations")
+		var migrateCfg Migrate
+		migrate.RegisterValue("migrate", &migrateCfg)
+
+		clic.Parse(ctx) // like `svc -database.url url migrate -migrate.dir ./migrations`
+
+		switch strings.Join(clic.Selected(), " ") {
+		case "migrate":
+			runMigrate(ctx, db, migrateCfg)
+		default:
+			serve(ctx, db)
+		}
+	}
+*/
+func Command(name, description string) *Set {
+	return CommandLine.Command(name, description)
+}
+
+// Selected returns names of commands selected by args after calling [Parse], or nil if no command is selected.
+func Selected() []string {
+	return CommandLine.Selected()
+}
+
+/*
+Provenance returns all registered fields, with current values and where they come from. It should be called after calling [Parse].
+
+Example:
+
+	package main
+
+	func main() {
+		ctx := context.Background()
+
+		clic.Parse(ctx)
+
+		for _, field := range clic.Provenance() {
+			slog.Info("config", "name", strings.Join(field.Name, "."), "value", field.Value, "from", field.Origin)
+		}
+	}
+*/
+func Provenance() []FieldProvenance {
+	return CommandLine.Provenance()
+}
+
+/*
+PrintConfigFlag registers a bool flag with the "name". If the flag is set, [Parse] prints the effective config in the format of the config file, with secrets redacted, and exits.
+
+Example:
+
+	package main
+
+	func main() {
+		ctx := context.Background()
+
+		clic.PrintConfigFlag("print-config")
+
+		clic.Parse(ctx) // exits after printing the config with "-print-config"
+	}
+*/
+func PrintConfigFlag(name string) {
+	CommandLine.PrintConfigFlag(name)
+}
+
+/*
+PrintTemplateFlag registers a bool flag with the "name". If the flag is set, [Parse] prints a config template in the format of the config file and exits. Fields in the template are set to default values, with descriptions as comments if the format allows it.
+
+Example:
+
+	package main
+
+	func main() {
+		ctx := context.Background()
+
+		clic.PrintTemplateFlag("config-template")
+
+		clic.Parse(ctx) // exits after printing the template with "-config-template"
+	}
+*/
+func PrintTemplateFlag(name string) {
+	CommandLine.PrintTemplateFlag(name)
+}
+
+// WriteTemplate writes a config template to "w" in the format of the config file.
+func WriteTemplate(w io.Writer) error {
+	return CommandLine.WriteTemplate(w)
+}
+
+// WriteConfig writes the effective config to "w" in the format of the config file, with secrets redacted. It should be called after calling [Parse].
+func WriteConfig(w io.Writer) error {
+	return CommandLine.WriteConfig(w)
+}
+
 // Parse parses configuration from [DefaultSources] and [os.Args].
 //
 // If any error happens during calling, "Parse()" prints that error on Stderr and calls [os.Exit] to exit with "125" code.
+// If a flag asks to quit early, like flags registered by [PrintConfigFlag] and [PrintTemplateFlag], "Parse()" calls [os.Exit] to exit with "0" code.
 func Parse(ctx context.Context) {
 	if err := CommandLine.Parse(ctx, os.Args[1:]); err != nil {
+		if errors.Is(err, source.ErrQuitEarly) {
+			os.Exit(0)
+		}
 		fmt.Fprintln(os.Stderr, "parse config error:", err)
 		os.Exit(125)
 	}
@@ -0,0 +1,235 @@
+package clic
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/googollee/clic/structtags"
+)
+
+// Command returns the subcommand with `name`, and creates it with `description` if it doesn't exist.
+//
+// A command is a [Set] with its own scopes, callbacks and flag set, and shares sources with its parent.
+// Scopes registered in the command are parsed only if the command is selected by args, like `svc migrate -dir ./migrations`,
+// and scopes of its parents are always parsed. Flags of parents could be set before or after the name of the command.
+//
+// Commands are selected by [Set.Parse] of the root set. Prefixes and keys of a command must not conflict with its parents.
+// A set with positional fields can't have commands, and it panics if `s` has any.
+func (s *Set) Command(name, description string) *Set {
+	if name == "" || strings.HasPrefix(name, "-") {
+		panic(fmt.Sprintf("invalid command name %q", name))
+	}
+
+	if hasArgs(s.fields) {
+		panic(fmt.Sprintf("command %q can't be added to %q, which has positional arguments", name, s.fullName()))
+	}
+
+	if cmd, ok := s.commands[name]; ok {
+		if cmd.description == "" {
+			cmd.description = description
+		}
+		return cmd
+	}
+
+	handling := flag.ContinueOnError
+	if fset, ok := s.root().fset.(interface{ ErrorHandling() flag.ErrorHandling }); ok {
+		handling = fset.ErrorHandling()
+	}
+
+	cmd := NewSet(flag.NewFlagSet(strings.TrimSpace(s.fullName()+" "+name), handling), s.sources...)
+	cmd.parent = s
+	cmd.name = name
+	cmd.description = description
+
+	root := s.root()
+	if root.printFlag != "" {
+		cmd.boolFlag(&root.printConfig, root.printFlag, printConfigUsage)
+	}
+	if root.templateFlag != "" {
+		cmd.boolFlag(&root.printTemplate, root.templateFlag, printTemplateUsage)
+	}
+
+	if s.commands == nil {
+		s.commands = make(map[string]*Set)
+	}
+	s.commands[name] = cmd
+	s.commandNames = append(s.commandNames, name)
+
+	return cmd
+}
+
+// CommandName returns names of the set from the root, like ["svc", "admin", "user"]. The name of the root is the name of its flag set.
+func (s *Set) CommandName() []string {
+	if s.parent == nil {
+		name := ""
+		if fset, ok := s.fset.(interface{ Name() string }); ok {
+			name = fset.Name()
+		}
+		return []string{name}
+	}
+
+	return append(s.parent.CommandName(), s.name)
+}
+
+// Selected returns names of commands selected by args after calling [Set.Parse], like ["admin", "user", "add"] for `svc admin user add`.
+// It returns nil if no command is selected.
+func (s *Set) Selected() []string {
+	s = s.root()
+
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	return slices.Clone(s.selected)
+}
+
+// fullName returns names of the set from the root, separated by spaces, like "svc admin user".
+func (s *Set) fullName() string {
+	return strings.TrimSpace(strings.Join(s.CommandName(), " "))
+}
+
+func (s *Set) root() *Set {
+	for s.parent != nil {
+		s = s.parent
+	}
+
+	return s
+}
+
+// parseFlags registers sources, parses flags of each command level and selects commands by names in `args`.
+// Scopes of selected commands are merged into the root set.
+// Sources are registered to the flag set of the last selected command, and it returns args with flags of all levels, which the flag set could parse again.
+func (s *Set) parseFlags(args []string) ([]string, error) {
+	if len(s.commands) > 0 && s.selected != nil {
+		return nil, fmt.Errorf("parse commands again")
+	}
+
+	fset := s.fset
+	cmd := s
+	var flagArgs []string
+
+	for {
+		for _, src := range s.sources {
+			if err := src.Register(fset, sourceFields(s.fields)); err != nil {
+				return nil, fmt.Errorf("prepare source %T error: %w", src, err)
+			}
+		}
+
+		withArgs := hasArgs(s.fields)
+		if cmdFlagSet, ok := fset.(*flag.FlagSet); ok && (cmd.parent != nil || len(cmd.commands) > 0 || withArgs) {
+			cmdFlagSet.Usage = cmd.usage(cmdFlagSet, s.fields)
+		}
+
+		// Flags of parents could be set at any level, so each level parses flags of all previous levels again.
+		args = append(flagArgs, args...)
+		argsFlagSet, ok := fset.(interface{ Args() []string })
+		if len(cmd.commands) == 0 {
+			if fset != nil && !fset.Parsed() {
+				if err := fset.Parse(args); err != nil {
+					return nil, err
+				}
+			}
+
+			s.positional = nil
+			if withArgs {
+				if !ok {
+					return nil, fmt.Errorf("flag set %T doesn't support positional arguments", fset)
+				}
+				s.positional = argsFlagSet.Args()
+			}
+			return args, nil
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("flag set %T doesn't support commands", fset)
+		}
+
+		if !fset.Parsed() {
+			if err := fset.Parse(args); err != nil {
+				return nil, err
+			}
+		}
+
+		rest := argsFlagSet.Args()
+		if len(rest) == 0 {
+			return args, nil
+		}
+		flagArgs = slices.Clone(args[:len(args)-len(rest)])
+
+		next, ok := cmd.commands[rest[0]]
+		if !ok {
+			return nil, fmt.Errorf("unknown command %q of %q, available commands: %s", rest[0], cmd.fullName(), strings.Join(cmd.commandNames, ", "))
+		}
+		if err := s.merge(next); err != nil {
+			return nil, err
+		}
+
+		cmd = next
+		args = rest[1:]
+		fset = next.fset
+		s.selected = append(s.selected, next.name)
+
+		if nextFlagSet, ok := fset.(*flag.FlagSet); ok {
+			if output, ok := s.fset.(interface{ Output() io.Writer }); ok {
+				nextFlagSet.SetOutput(output.Output())
+			}
+		}
+	}
+}
+
+// merge merges scopes of the command `cmd` into the root set.
+func (s *Set) merge(cmd *Set) error {
+	for _, prefix := range cmd.prefixes {
+		if _, exist := s.configs[prefix]; exist {
+			return fmt.Errorf("config %q of command %q conflicts with a config of its parents", prefix, cmd.fullName())
+		}
+	}
+
+	if err := s.indexKeys(cmd.fields); err != nil {
+		return err
+	}
+
+	s.fields = append(s.fields, cmd.fields...)
+	s.baseOrigins = append(s.baseOrigins, cmd.baseOrigins...)
+	for _, prefix := range cmd.prefixes {
+		s.configs[prefix] = cmd.configs[prefix]
+		s.prefixes = append(s.prefixes, prefix)
+	}
+	for prefix, depends := range cmd.depends {
+		s.depends[prefix] = append(s.depends[prefix], depends...)
+	}
+
+	return nil
+}
+
+// usage returns the usage function of the flag set `fset` of the set, with a usage line of subcommands or positional arguments in `fields`.
+func (s *Set) usage(fset *flag.FlagSet, fields []structtags.Field) func() {
+	return func() {
+		w := fset.Output()
+
+		line := []string{fset.Name(), "[flags]"}
+		if len(s.commandNames) > 0 {
+			line = append(line, "<command>")
+		} else if args := argsLine(fields); args != "" {
+			line = append(line, args)
+		}
+		fmt.Fprintf(w, "Usage: %s\n", strings.TrimSpace(strings.Join(line, " ")))
+		if s.description != "" {
+			fmt.Fprintf(w, "%s\n", s.description)
+		}
+		fset.PrintDefaults()
+
+		if len(s.commandNames) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "Commands:\n")
+		for _, name := range s.commandNames {
+			fmt.Fprintf(w, "  %s\n", name)
+			if description := s.commands[name].description; description != "" {
+				fmt.Fprintf(w, "    \t%s\n", description)
+			}
+		}
+	}
+}
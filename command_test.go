@@ -0,0 +1,303 @@
+package clic_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic"
+	"github.com/googollee/clic/source"
+)
+
+func ExampleSet_Command() {
+	type Database struct {
+		URL string `clic:"url,./database.sqlite,the url of the database"`
+	}
+	type Migrate struct {
+		Dir string `clic:"dir,./migrations,the directory of migrations"`
+	}
+	type Serve struct {
+		Addr string `clic:"addr,:8080,the address to listen"`
+	}
+
+	fset := flag.NewFlagSet("svc", flag.ContinueOnError)
+	set := clic.NewSet(fset)
+
+	var db Database
+	set.RegisterValue("database", &db)
+
+	var migrate Migrate
+	set.Command("migrate", "run database migrations").RegisterValue("migrate", &migrate)
+
+	set.Command("serve", "start the server").RegisterCallback("serve", func(ctx context.Context, serve *Serve) error {
+		fmt.Println("serve at:", serve.Addr)
+		return nil
+	})
+
+	args := []string{"-database.url", "url", "migrate", "-migrate.dir", "./sql", "up"}
+	if err := set.Parse(context.Background(), args); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	fmt.Println("command:", set.Selected())
+	fmt.Println("database:", db)
+	fmt.Println("migrate:", migrate)
+
+	// Output:
+	// command: [migrate]
+	// database: {url}
+	// migrate: {./sql}
+}
+
+type commandConfig struct {
+	Value string `clic:"value,default"`
+}
+
+// newCommandSet creates a set with commands `svc [migrate|serve|admin user add]`, and all configs are named with the command path.
+func newCommandSet() (*clic.Set, map[string]*commandConfig, *[]string) {
+	fset := flag.NewFlagSet("svc", flag.ContinueOnError)
+	fset.SetOutput(&bytes.Buffer{})
+	set := clic.NewSet(fset, source.Flag(), source.Env())
+
+	configs := make(map[string]*commandConfig)
+	var called []string
+	register := func(set *clic.Set, prefix string) {
+		configs[prefix] = &commandConfig{}
+		set.RegisterValue(prefix, configs[prefix])
+		set.RegisterCallback(prefix+"_init", func(ctx context.Context, cfg *commandConfig) error {
+			called = append(called, prefix)
+			return nil
+		})
+	}
+
+	register(set, "global")
+	register(set.Command("migrate", "run migrations"), "migrate")
+	register(set.Command("serve", "start the server"), "serve")
+	register(set.Command("admin", "").Command("user", "manage users").Command("add", "add a user"), "add")
+
+	return set, configs, &called
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		env        map[string]string
+		wantCmd    []string
+		wantValues map[string]string
+		wantCalled []string
+	}{
+		{
+			name:       "NoCommand",
+			args:       []string{"-global.value", "g"},
+			wantValues: map[string]string{"global": "g", "migrate": "", "serve": "", "add": ""},
+			wantCalled: []string{"global"},
+		},
+		{
+			name:       "Command",
+			args:       []string{"-global.value", "g", "migrate", "-migrate.value", "m"},
+			wantCmd:    []string{"migrate"},
+			wantValues: map[string]string{"global": "g", "migrate": "m", "serve": "", "add": ""},
+			wantCalled: []string{"global", "migrate"},
+		},
+		{
+			name:       "GlobalFlagAfterCommand",
+			args:       []string{"serve", "-global.value", "g"},
+			wantCmd:    []string{"serve"},
+			wantValues: map[string]string{"global": "g", "migrate": "", "serve": "default", "add": ""},
+			wantCalled: []string{"global", "serve"},
+		},
+		{
+			name:       "Env",
+			args:       []string{"migrate"},
+			env:        map[string]string{"GLOBAL_VALUE": "g", "MIGRATE_VALUE": "m", "SERVE_VALUE": "s"},
+			wantCmd:    []string{"migrate"},
+			wantValues: map[string]string{"global": "g", "migrate": "m", "serve": "", "add": ""},
+			wantCalled: []string{"global", "migrate"},
+		},
+		{
+			name:       "Nested",
+			args:       []string{"admin", "user", "-global.value", "g", "add", "-add.value", "a"},
+			wantCmd:    []string{"admin", "user", "add"},
+			wantValues: map[string]string{"global": "g", "migrate": "", "serve": "", "add": "a"},
+			wantCalled: []string{"global", "add"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			set, configs, called := newCommandSet()
+			if err := set.Parse(t.Context(), tc.args); err != nil {
+				t.Fatalf("set.Parse(%q) error: %v", tc.args, err)
+			}
+
+			if diff := cmp.Diff(set.Selected(), tc.wantCmd); diff != "" {
+				t.Errorf("set.Selected() diff: (-got, +want)\n%s", diff)
+			}
+
+			values := make(map[string]string)
+			for prefix, cfg := range configs {
+				values[prefix] = cfg.Value
+			}
+			if diff := cmp.Diff(values, tc.wantValues); diff != "" {
+				t.Errorf("config values diff: (-got, +want)\n%s", diff)
+			}
+
+			if diff := cmp.Diff(*called, tc.wantCalled); diff != "" {
+				t.Errorf("called callbacks diff: (-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"UnknownCommand", []string{"unknown"}},
+		{"UnknownNested", []string{"admin", "group"}},
+		{"FlagOfOtherCommand", []string{"migrate", "-serve.value", "s"}},
+		{"FlagOfCommandBeforeName", []string{"-migrate.value", "m", "migrate"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set, _, _ := newCommandSet()
+			if err := set.Parse(t.Context(), tc.args); err == nil {
+				t.Errorf("set.Parse(%q) returns no error, want an error", tc.args)
+			}
+		})
+	}
+
+	t.Run("ParseCommand", func(t *testing.T) {
+		set, _, _ := newCommandSet()
+		if err := set.Command("migrate", "").Parse(t.Context(), nil); err == nil {
+			t.Errorf("command.Parse() returns no error, want an error")
+		}
+	})
+}
+
+func TestCommandHelp(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Root",
+			args: []string{"-h"},
+			want: `Usage: svc [flags] <command>
+  -global.value value
+    	 (default default)
+Commands:
+  migrate
+    	run migrations
+  serve
+    	start the server
+  admin
+`,
+		},
+		{
+			name: "Command",
+			args: []string{"admin", "user", "-h"},
+			want: `Usage: svc admin user [flags] <command>
+manage users
+  -global.value value
+    	 (default default)
+Commands:
+  add
+    	add a user
+`,
+		},
+		{
+			name: "Leaf",
+			args: []string{"migrate", "-h"},
+			want: `Usage: svc migrate [flags]
+run migrations
+  -global.value value
+    	 (default default)
+  -migrate.value value
+    	 (default default)
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var output bytes.Buffer
+			fset := flag.NewFlagSet("svc", flag.ContinueOnError)
+			fset.SetOutput(&output)
+			set := clic.NewSet(fset, source.Flag())
+
+			var global, migrate, add commandConfig
+			set.RegisterValue("global", &global)
+			set.Command("migrate", "run migrations").RegisterValue("migrate", &migrate)
+			set.Command("serve", "start the server")
+			set.Command("admin", "").Command("user", "manage users").Command("add", "add a user").RegisterValue("add", &add)
+
+			err := set.Parse(t.Context(), tc.args)
+			if !errors.Is(err, flag.ErrHelp) {
+				t.Fatalf("set.Parse(%q) = %v, want: %v", tc.args, err, flag.ErrHelp)
+			}
+
+			if diff := cmp.Diff(output.String(), tc.want); diff != "" {
+				t.Errorf("help diff: (-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommandConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(set *clic.Set)
+	}{
+		{"SamePrefix", func(set *clic.Set) {
+			set.RegisterValue("global", &commandConfig{})
+			set.Command("cmd", "").RegisterValue("global", &commandConfig{})
+		}},
+		{"SameKey", func(set *clic.Set) {
+			set.RegisterValue("global", &commandConfig{})
+			set.Command("cmd", "").RegisterValue("other", &struct {
+				Value string `clic:"value" flag:"global.value"`
+			}{})
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set := clic.NewSet(flag.NewFlagSet("svc", flag.ContinueOnError), source.Flag())
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("registering passes, want a panic")
+				} else if !strings.Contains(fmt.Sprint(r), "global") {
+					t.Errorf("panic = %v, want mentioning %q", r, "global")
+				}
+			}()
+
+			tc.register(set)
+		})
+	}
+
+	t.Run("RegisterParentLater", func(t *testing.T) {
+		set := clic.NewSet(flag.NewFlagSet("svc", flag.ContinueOnError), source.Flag())
+		set.Command("cmd", "").RegisterValue("global", &commandConfig{})
+		set.RegisterValue("global", &commandConfig{})
+
+		if err := set.Parse(t.Context(), []string{"cmd"}); err == nil {
+			t.Errorf("set.Parse() returns no error, want an error")
+		}
+	})
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 )
 
 type config struct {
-	value    reflect.Value
+	value    reflect.Value // value is the pointer to the value which users read. It's updated after parsing successfully.
 	callback reflect.Value
+	reloads  []reflect.Value
+
+	staging  reflect.Value // staging is the pointer to the value which sources parse into.
+	baseline reflect.Value // baseline is the pointer to the value with defaults after registration. Reloading starts from it.
+	snapshot atomic.Value  // snapshot is a pointer to an immutable copy of the value, which is replaced after parsing successfully.
 }
 
 func newConfigValue(value any) *config {
@@ -78,3 +84,25 @@ func (c *config) Callback(ctx context.Context) error {
 func (c *config) Value() reflect.Value {
 	return c.value
 }
+
+// newReloadCallback checks the reload callback `callback` for the config with `valType`.
+func newReloadCallback(callback any, valType reflect.Type) (reflect.Value, error) {
+	if callback == nil {
+		return reflect.Value{}, fmt.Errorf("register with nil reload callback")
+	}
+
+	f := reflect.ValueOf(callback)
+	want := reflect.FuncOf([]reflect.Type{reflect.TypeFor[context.Context](), valType, valType}, nil, false)
+	if f.Type() != want {
+		return reflect.Value{}, fmt.Errorf("register with invalid reload callback %T, must be `%s`", callback, want)
+	}
+
+	return f, nil
+}
+
+// Reload calls reload callbacks with the old value and the new value.
+func (c *config) Reload(ctx context.Context, old, new reflect.Value) {
+	for _, f := range c.reloads {
+		f.Call([]reflect.Value{reflect.ValueOf(ctx), old, new})
+	}
+}
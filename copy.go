@@ -0,0 +1,88 @@
+package clic
+
+import (
+	"reflect"
+)
+
+// copyValue copies `src` to `dst` deeply, so they share no pointer, slice or map after copying.
+// Non-nil pointers in `dst` are kept, and values they point to are overwritten, because fields parsed by [structtags.ParseStruct] refer to them.
+// Unexported fields of a struct are left alone, except a struct without any exported field, like [time.Time], which is an opaque value and copied as a whole.
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+		}
+		copyValue(dst.Elem(), src.Elem())
+
+	case reflect.Struct:
+		t := src.Type()
+		if isOpaque(t) {
+			if dst.CanSet() {
+				dst.Set(src)
+			}
+			return
+		}
+		for i := range t.NumField() {
+			// Exported fields of an embedded struct are promoted, even if the embedded type is unexported.
+			if field := t.Field(i); field.IsExported() || (field.Anonymous && field.Type.Kind() == reflect.Struct) {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+
+	case reflect.Array:
+		for i := range src.Len() {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		ret := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := range src.Len() {
+			copyValue(ret.Index(i), src.Index(i))
+		}
+		dst.Set(ret)
+
+	case reflect.Map:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		ret := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			copyValue(value, iter.Value())
+			ret.SetMapIndex(iter.Key(), value)
+		}
+		dst.Set(ret)
+
+	default:
+		dst.Set(src)
+	}
+}
+
+// isOpaque returns true if the struct type `t` has no exported field, like [time.Time].
+func isOpaque(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cloneValue returns a new pointer to a deep copy of the value which `ptr` points to.
+func cloneValue(ptr reflect.Value) reflect.Value {
+	ret := reflect.New(ptr.Type().Elem())
+	copyValue(ret.Elem(), ptr.Elem())
+	return ret
+}
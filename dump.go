@@ -0,0 +1,90 @@
+package clic
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/googollee/clic/source"
+)
+
+// PrintConfigFlag registers a bool flag with `name`, like "print-config". If the flag is set, [Set.Parse] writes the effective config to [os.Stdout] by [Set.WriteConfig] after parsing, and returns [source.ErrQuitEarly].
+//
+// It panics if a registered field uses the flag `name`.
+func (s *Set) PrintConfigFlag(name string) {
+	s.checkFlag(name)
+	s.printFlag = name
+	s.boolFlag(&s.printConfig, name, printConfigUsage)
+}
+
+const (
+	printConfigUsage   = "print the effective config and quit"
+	printTemplateUsage = "print a config template and quit"
+)
+
+// WriteConfig writes current values of all fields to `w`, in the format of the first source which is a [source.ConfigWriter], like the config file source. Secrets are redacted.
+//
+// It should be called after calling [Set.Parse].
+func (s *Set) WriteConfig(w io.Writer) error {
+	s = s.root()
+
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	return s.writeConfig(w)
+}
+
+func (s *Set) writeConfig(w io.Writer) error {
+	for _, src := range s.sources {
+		if writer, ok := src.(source.ConfigWriter); ok {
+			return writer.WriteConfig(w)
+		}
+	}
+
+	return fmt.Errorf("no source could write the config")
+}
+
+// PrintTemplateFlag registers a bool flag with `name`, like "config-template". If the flag is set, [Set.Parse] writes a config template to [os.Stdout] by [Set.WriteTemplate] before parsing any source, and returns [source.ErrQuitEarly].
+//
+// It panics if a registered field uses the flag `name`.
+func (s *Set) PrintTemplateFlag(name string) {
+	s.checkFlag(name)
+	s.templateFlag = name
+	s.boolFlag(&s.printTemplate, name, printTemplateUsage)
+}
+
+// boolFlag registers a bool flag to the flag sets of the set and its commands. Commands created later register the flag in [Set.Command].
+func (s *Set) boolFlag(p *bool, name, usage string) {
+	if s.fset != nil {
+		s.fset.BoolVar(p, name, false, usage)
+	}
+
+	for _, cmd := range s.commands {
+		cmd.boolFlag(p, name, usage)
+	}
+}
+
+// checkFlag panics if a field of the set or its commands uses the flag `name`.
+func (s *Set) checkFlag(name string) {
+	key := source.FlagKey(name)
+	for _, keys := range s.keys {
+		if owner, exist := keys[key]; exist {
+			panic(fmt.Sprintf("%s of field %v conflicts with a built-in flag", key, owner))
+		}
+	}
+
+	for _, cmd := range s.commands {
+		cmd.checkFlag(name)
+	}
+}
+
+// WriteTemplate writes a config template of all registered fields to `w`, in the format of the first source which is a [source.TemplateWriter], like the config file source.
+// Each field is set to its default value, except that a secret field is left empty, and each description is a comment if the format allows it, like YAML and TOML.
+func (s *Set) WriteTemplate(w io.Writer) error {
+	for _, src := range s.sources {
+		if writer, ok := src.(source.TemplateWriter); ok {
+			return writer.WriteTemplate(w, sourceFields(s.fields))
+		}
+	}
+
+	return fmt.Errorf("no source could write a config template")
+}
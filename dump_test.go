@@ -0,0 +1,140 @@
+package clic_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/googollee/clic"
+	"github.com/googollee/clic/source"
+)
+
+func ExampleSet_PrintConfigFlag() {
+	// code starts
+	type Database struct {
+		URL      string            `clic:"url,postgres://localhost/db"`
+		Password string            `clic:"password,admin" secret:"true"`
+		Timeout  time.Duration     `clic:"timeout,30s"`
+		PoolSize int               `clic:"pool_size,10"`
+		Replicas []string          `clic:"replicas" default:"replica1,replica2"`
+		Labels   map[string]string `clic:"labels,team=infra"`
+	}
+
+	set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError),
+		source.Flag(),
+		source.File(source.FileFormat(source.YAML{})),
+		source.Env(),
+	)
+	set.PrintConfigFlag("print-config")
+
+	var db Database
+	set.RegisterValue("database", &db)
+
+	ctx := context.Background()
+	err := set.Parse(ctx, []string{"-print-config", "-database.pool_size", "20"})
+	if !errors.Is(err, source.ErrQuitEarly) {
+		log.Fatal("parse error:", err)
+	}
+
+	// Output:
+	// database:
+	//   url: postgres://localhost/db
+	//   password: '******'
+	//   timeout: 30s
+	//   pool_size: 20
+	//   replicas:
+	//     - replica1
+	//     - replica2
+	//   labels:
+	//     team: infra
+}
+
+func ExampleSet_PrintTemplateFlag() {
+	// code starts
+	type Database struct {
+		URL  string `clic:"url,,the url of the database" required:"true"`
+		Pool struct {
+			Size    int           `clic:"size,10,the size of the pool"`
+			Timeout time.Duration `clic:"timeout,30s"`
+		} `clic:"pool"`
+		Replicas []string `clic:"replicas" desc:"hosts of replicas"`
+	}
+
+	set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError),
+		source.Flag(),
+		source.File(source.FileFormat(source.TOML{})),
+		source.Env(),
+	)
+	set.PrintTemplateFlag("config-template")
+
+	var db Database
+	set.RegisterValue("database", &db)
+
+	ctx := context.Background()
+	err := set.Parse(ctx, []string{"-config-template"})
+	if !errors.Is(err, source.ErrQuitEarly) {
+		log.Fatal("parse error:", err)
+	}
+
+	// Output:
+	// [database]
+	//   # the url of the database
+	//   url = ""
+	//   # hosts of replicas
+	//   replicas = []
+	//
+	//   [database.pool]
+	//     # the size of the pool
+	//     size = 10
+	//     timeout = "30s"
+}
+
+func ExampleSet_PrintConfigFlag_command() {
+	// code starts
+	type Migrate struct {
+		Dir string `clic:"dir,./migrations"`
+	}
+
+	set := clic.NewSet(flag.NewFlagSet("svc", flag.ContinueOnError),
+		source.Flag(),
+		source.File(source.FileFormat(source.YAML{})),
+	)
+	set.PrintConfigFlag("print-config")
+
+	var migrate Migrate
+	set.Command("migrate", "migrate the database").RegisterValue("migrate", &migrate)
+
+	ctx := context.Background()
+	err := set.Parse(ctx, []string{"migrate", "-print-config"})
+	if !errors.Is(err, source.ErrQuitEarly) {
+		log.Fatal("parse error:", err)
+	}
+
+	// Output:
+	// migrate:
+	//   dir: ./migrations
+}
+
+func TestParseTwiceWithPrintFlags(t *testing.T) {
+	type Config struct {
+		Value int `clic:"value,1"`
+	}
+
+	set := clic.NewSet(flag.NewFlagSet("svc", flag.ContinueOnError), source.Env())
+	set.Command("before", "")
+	set.PrintConfigFlag("print-config")
+	set.PrintTemplateFlag("config-template")
+	set.Command("after", "")
+
+	var cfg Config
+	set.RegisterValue("c", &cfg)
+
+	for range 2 {
+		if err := set.Parse(t.Context(), []string{}); err != nil {
+			t.Fatalf("set.Parse() returns error: %v", err)
+		}
+	}
+}
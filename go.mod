@@ -2,4 +2,8 @@ module github.com/googollee/clic
 
 go 1.24
 
-require github.com/google/go-cmp v0.7.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/google/go-cmp v0.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,45 @@
+package clic_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/googollee/clic"
+)
+
+func Example_mapField() {
+	// prepare env
+	if err := os.Setenv("TENANT_RATE_LIMITS", "alice=10,bob=20"); err != nil {
+		log.Fatal("set env error:", err)
+	}
+
+	// prepare args
+	args := []string{"-tenant.labels", "team=infra", "-tenant.labels", "env=prod"}
+
+	// code starts
+	type Tenant struct {
+		Labels     map[string]string `clic:"labels,,the labels of the tenant"`
+		RateLimits map[string]int    `clic:"rate_limits,,the rate limits of users"`
+	}
+
+	fset := flag.NewFlagSet("", flag.PanicOnError)
+	set := clic.NewSet(fset)
+
+	var cfg Tenant
+	set.RegisterValue("tenant", &cfg)
+
+	ctx := context.Background()
+	if err := set.Parse(ctx, args); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	fmt.Println("Labels:", cfg.Labels)
+	fmt.Println("RateLimits:", cfg.RateLimits)
+
+	// Output:
+	// Labels: map[env:prod team:infra]
+	// RateLimits: map[alice:10 bob:20]
+}
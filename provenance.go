@@ -0,0 +1,33 @@
+package clic
+
+import (
+	"github.com/googollee/clic/structtags"
+)
+
+// FieldProvenance describes the current value of a field and where it comes from.
+type FieldProvenance struct {
+	Name   []string          // Name is the full name of the field, starting with the scope name.
+	Value  string            // Value is the text of the value, with secrets redacted.
+	Origin structtags.Origin // Origin is where the value comes from. Its Source is empty if no one sets the field.
+}
+
+// Provenance returns all registered fields, with current values and where they come from, in the order of registration.
+// It's useful to log the effective configuration after calling [Set.Parse].
+func (s *Set) Provenance() []FieldProvenance {
+	s = s.root()
+
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	ret := make([]FieldProvenance, 0, len(s.fields))
+	for _, field := range s.fields {
+		value, _ := field.RedactedText()
+		ret = append(ret, FieldProvenance{
+			Name:   field.Name,
+			Value:  string(value),
+			Origin: field.Origin(),
+		})
+	}
+
+	return ret
+}
@@ -0,0 +1,75 @@
+package clic_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/googollee/clic"
+	"github.com/googollee/clic/source"
+)
+
+func ExampleSet_Provenance() {
+	// prepare env
+	if err := os.Setenv("SERVER_HOST", "0.0.0.0"); err != nil {
+		log.Fatal("set env error:", err)
+	}
+
+	// prepare config file
+	cfgFile, err := os.CreateTemp("", "config_*.yaml")
+	if err != nil {
+		log.Fatal("create temp file error:", err)
+	}
+	defer os.Remove(cfgFile.Name())
+
+	if _, err := cfgFile.WriteString("server:\n  port: 8080\n  token: abc\n"); err != nil {
+		log.Fatal("write temp file error:", err)
+	}
+
+	if err := cfgFile.Close(); err != nil {
+		log.Fatal("close temp file error:", err)
+	}
+
+	// prepare args
+	args := []string{"-config", cfgFile.Name(), "-server.debug", "true"}
+
+	// code starts
+	type Server struct {
+		Host    string `clic:"host,localhost"`
+		Port    int    `clic:"port,80"`
+		Token   string `clic:"token" secret:"true"`
+		Debug   bool   `clic:"debug"`
+		Timeout string `clic:"timeout,30s"`
+		Prefix  string `clic:"prefix"`
+	}
+
+	set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError),
+		source.Flag(),
+		source.File(source.FileFormat(source.YAML{})),
+		source.Env(),
+	)
+
+	var server Server
+	set.RegisterValue("server", &server)
+
+	ctx := context.Background()
+	if err := set.Parse(ctx, args); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	for _, field := range set.Provenance() {
+		origin := strings.ReplaceAll(field.Origin.String(), cfgFile.Name(), "config.yaml")
+		fmt.Printf("%s=%q from %q\n", strings.Join(field.Name, "."), field.Value, origin)
+	}
+
+	// Output:
+	// server.host="0.0.0.0" from "env SERVER_HOST"
+	// server.port="8080" from "file server.port in config.yaml:2"
+	// server.token="******" from "file server.token in config.yaml:3"
+	// server.debug="true" from "flag -server.debug"
+	// server.timeout="30s" from "default"
+	// server.prefix="" from ""
+}
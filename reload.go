@@ -0,0 +1,207 @@
+package clic
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"time"
+
+	"github.com/googollee/clic/source"
+	"github.com/googollee/clic/structtags"
+)
+
+// OnReload registers a reload callback of the "prefix" scope, which must be registered before.
+// The callback must be `func(ctx context.Context, old, new *Type)`, where `Type` is the type of the scope.
+// It's called with copies of the old value and the new value after a reload changes the scope.
+func (s *Set) OnReload(prefix string, callback any) {
+	config, ok := s.configs[prefix]
+	if !ok {
+		panic(fmt.Sprintf("register reload callback for config %q, which is not registered", prefix))
+	}
+
+	f, err := newReloadCallback(callback, config.Value().Type())
+	if err != nil {
+		panic(err)
+	}
+
+	config.reloads = append(config.reloads, f)
+}
+
+// Reload parses all sources again, with the same args and the same precedence as [Set.Parse].
+// Fields not set by any source go back to default values.
+//
+// If any source fails or any field is invalid, values stay the same as the last successful parsing, and Reload returns the error.
+// Otherwise, values are updated, and reload callbacks of changed scopes are called.
+func (s *Set) Reload(ctx context.Context) error {
+	s = s.root()
+
+	changes, err := s.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Callbacks are called without holding parseMu, so they could read the set, like [Set.Provenance].
+	for _, change := range changes {
+		s.configs[change.prefix].Reload(ctx, change.old, change.new)
+	}
+
+	return nil
+}
+
+// configChange is a scope changed by reloading, with copies of the old value and the new value.
+type configChange struct {
+	prefix   string
+	old, new reflect.Value
+}
+
+// reload parses all sources again and publishes values, and returns changed scopes with reload callbacks in the order of calling callbacks.
+func (s *Set) reload(ctx context.Context) ([]configChange, error) {
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	if !s.parsed {
+		return nil, fmt.Errorf("reload config before parsing")
+	}
+
+	origins := make([]structtags.Origin, 0, len(s.fields))
+	for i, field := range s.fields {
+		origins = append(origins, field.Origin())
+		field.SetOrigin(s.baseOrigins[i])
+	}
+	for _, prefix := range s.prefixes {
+		config := s.configs[prefix]
+		copyValue(config.staging.Elem(), config.baseline.Elem())
+	}
+
+	if err := s.parseSources(ctx, s.args); err != nil {
+		for i, field := range s.fields {
+			field.SetOrigin(origins[i])
+		}
+		for _, prefix := range s.prefixes {
+			config := s.configs[prefix]
+			copyValue(config.staging.Elem(), config.value.Elem())
+		}
+		return nil, err
+	}
+
+	olds := make(map[string]reflect.Value)
+	for _, prefix := range s.prefixes {
+		config := s.configs[prefix]
+		if len(config.reloads) > 0 && !reflect.DeepEqual(config.value.Elem().Interface(), config.staging.Elem().Interface()) {
+			olds[prefix] = cloneValue(config.value)
+		}
+	}
+
+	s.publish()
+
+	prefixes, err := s.sortedPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []configChange
+	for _, prefix := range prefixes {
+		if old, ok := olds[prefix]; ok {
+			ret = append(ret, configChange{prefix: prefix, old: old, new: cloneValue(s.configs[prefix].value)})
+		}
+	}
+
+	return ret, nil
+}
+
+// Watch checks sources every `interval`, and reloads the config by [Set.Reload] if any source changes, like a config file.
+// A failed reloading is logged, and the last good config stays in effect.
+//
+// Watch blocks until `ctx` is done, and returns the error of `ctx`. It returns an error at once if `interval` isn't positive.
+func (s *Set) Watch(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid watch interval: %v", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if !s.changed() {
+			continue
+		}
+
+		if err := s.Reload(ctx); err != nil {
+			slog.ErrorContext(ctx, "reload config error", "error", err)
+		}
+	}
+}
+
+// ReloadOnSignal reloads the config by [Set.Reload] when the process receives one of `sigs`, or SIGHUP if `sigs` is empty.
+// On platforms without SIGHUP, like js, `sigs` must not be empty.
+// A failed reloading is logged, and the last good config stays in effect.
+//
+// ReloadOnSignal blocks until `ctx` is done, and returns the error of `ctx`.
+func (s *Set) ReloadOnSignal(ctx context.Context, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = defaultReloadSignals
+	}
+	if len(sigs) == 0 {
+		return fmt.Errorf("no signal to reload the config")
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-ch:
+			slog.InfoContext(ctx, "reload config", "signal", sig.String())
+		}
+
+		if err := s.Reload(ctx); err != nil {
+			slog.ErrorContext(ctx, "reload config error", "error", err)
+		}
+	}
+}
+
+// RLock locks registered values for reading. [Set.Parse] and [Set.Reload] wait until all readers call [Set.RUnlock] before updating values,
+// so a reader holding the lock never sees a half-updated value.
+//
+// Reading a value without the lock is safe only if no one reloads the config.
+// Slices and maps in a value are replaced when reloading, but values which pointer fields point to are overwritten in place.
+func (s *Set) RLock() {
+	s.root().valueMu.RLock()
+}
+
+// RUnlock undoes a single [Set.RLock] call.
+func (s *Set) RUnlock() {
+	s.root().valueMu.RUnlock()
+}
+
+// changed returns true if any source changes after the last parsing.
+func (s *Set) changed() bool {
+	s = s.root()
+
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	if !s.parsed {
+		return false
+	}
+
+	for _, src := range s.sources {
+		if watcher, ok := src.(source.Watcher); ok && watcher.Changed() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !js
+
+package clic
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignals are signals which [Set.ReloadOnSignal] listens to if no signal is given.
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,8 @@
+//go:build js
+
+package clic
+
+import "os"
+
+// defaultReloadSignals is empty, because SIGHUP is not defined on the platform. Callers of [Set.ReloadOnSignal] must pass signals.
+var defaultReloadSignals []os.Signal
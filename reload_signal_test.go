@@ -0,0 +1,74 @@
+//go:build !js
+
+package clic_test
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	reloaded := make(chan int, 1)
+	set.OnReload("c", func(ctx context.Context, old, new *reloadConfig) {
+		reloaded <- new.Limit
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	// catch SIGHUP before set.ReloadOnSignal() starts, which kills the process by default.
+	ignore := make(chan os.Signal, 1)
+	signal.Notify(ignore, syscall.SIGHUP)
+	defer signal.Stop(ignore)
+
+	done := make(chan error)
+	go func() {
+		done <- set.ReloadOnSignal(ctx)
+	}()
+
+	writeFile(t, path, "c:\n  limit: 30\n")
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("find the current process error: %v", err)
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+
+	for waiting := true; waiting; {
+		if err := process.Signal(syscall.SIGHUP); err != nil {
+			t.Skipf("send SIGHUP error: %v", err)
+		}
+
+		select {
+		case limit := <-reloaded:
+			if limit != 30 {
+				t.Errorf("reloaded limit = %d, want: %d", limit, 30)
+			}
+			waiting = false
+		case <-ticker.C:
+		case <-timeout:
+			t.Fatalf("no reloading after receiving SIGHUP")
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("set.ReloadOnSignal() = %v, want: %v", err, context.Canceled)
+	}
+}
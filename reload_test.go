@@ -0,0 +1,388 @@
+package clic_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic"
+	"github.com/googollee/clic/source"
+)
+
+type reloadConfig struct {
+	Level   string         `clic:"level,info" enum:"debug,info,warn"`
+	Limit   int            `clic:"limit,10"`
+	Pointer *int           `clic:"pointer,1"`
+	Tags    []string       `clic:"tags"`
+	Limits  map[string]int `clic:"limits"`
+}
+
+func newReloadSet(t *testing.T, content string) (*clic.Set, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, path, content)
+
+	set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError),
+		source.Flag(),
+		source.File(source.FileFormat(source.YAML{})),
+		source.Env(),
+	)
+
+	return set, path
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write file %s error: %v", path, err)
+	}
+
+	// make sure the modification time changes.
+	modTime := time.Now().Add(time.Duration(len(content)) * time.Second)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("change times of file %s error: %v", path, err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  level: debug\n  limit: 20\n  tags: [a]\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	type change struct{ Old, New reloadConfig }
+	var changes []change
+	set.OnReload("c", func(ctx context.Context, old, new *reloadConfig) {
+		changes = append(changes, change{*old, *new})
+	})
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path, "-c.limit", "30"}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+	pointer := cfg.Pointer
+
+	one := 1
+	want := reloadConfig{Level: "debug", Limit: 30, Pointer: &one, Tags: []string{"a"}}
+	if diff := cmp.Diff(cfg, want); diff != "" {
+		t.Fatalf("after set.Parse(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	t.Run("Unchanged", func(t *testing.T) {
+		if err := set.Reload(ctx); err != nil {
+			t.Fatalf("set.Reload() error: %v", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("reload callbacks are called with %v, want no call", changes)
+		}
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		writeFile(t, path, "c:\n  limit: 40\n  pointer: 2\n  limits:\n    a: 1\n")
+		if err := set.Reload(ctx); err != nil {
+			t.Fatalf("set.Reload() error: %v", err)
+		}
+
+		two := 2
+		newWant := reloadConfig{Level: "info", Limit: 30, Pointer: &two, Limits: map[string]int{"a": 1}}
+		if diff := cmp.Diff(cfg, newWant); diff != "" {
+			t.Errorf("after set.Reload(), config diff: (-got, +want)\n%s", diff)
+		}
+		if cfg.Pointer != pointer {
+			t.Errorf("after set.Reload(), the pointer field is replaced, want updating in place")
+		}
+
+		wantChanges := []change{{want, newWant}}
+		if diff := cmp.Diff(changes, wantChanges); diff != "" {
+			t.Errorf("reload callbacks diff: (-got, +want)\n%s", diff)
+		}
+		want = newWant
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		changes = nil
+		for _, content := range []string{"c:\n  level: error\n", "c:\n  limit: abc\n", "c: ["} {
+			writeFile(t, path, content)
+			if err := set.Reload(ctx); err == nil {
+				t.Errorf("set.Reload() with %q returns no error, want an error", content)
+			}
+
+			if diff := cmp.Diff(cfg, want); diff != "" {
+				t.Errorf("after set.Reload() with %q, config diff: (-got, +want)\n%s", content, diff)
+			}
+		}
+		if len(changes) != 0 {
+			t.Errorf("reload callbacks are called with %v, want no call", changes)
+		}
+
+		for _, field := range set.Provenance() {
+			if field.Name[1] == "pointer" && field.Value != "2" {
+				t.Errorf("after a failed reload, provenance of %v = %q, want: %q", field.Name, field.Value, "2")
+			}
+		}
+	})
+}
+
+func TestReloadBeforeParse(t *testing.T) {
+	set, _ := newReloadSet(t, "")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	if err := set.Reload(t.Context()); err == nil {
+		t.Errorf("set.Reload() before set.Parse() returns no error, want an error")
+	}
+}
+
+func TestInvalidReloadCallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		callback any
+	}{
+		{"NotRegistered", "other", func(context.Context, *reloadConfig, *reloadConfig) {}},
+		{"Nil", "c", nil},
+		{"WrongType", "c", func(context.Context, *int, *int) {}},
+		{"NoContext", "c", func(*reloadConfig, *reloadConfig) {}},
+		{"ReturnError", "c", func(context.Context, *reloadConfig, *reloadConfig) error { return nil }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			set, _ := newReloadSet(t, "")
+
+			var cfg reloadConfig
+			set.RegisterValue("c", &cfg)
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("set.OnReload() passes, want a panic")
+				}
+			}()
+
+			set.OnReload(tc.prefix, tc.callback)
+		})
+	}
+}
+
+func TestWatch(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	reloaded := make(chan int, 1)
+	set.OnReload("c", func(ctx context.Context, old, new *reloadConfig) {
+		reloaded <- new.Limit
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- set.Watch(ctx, 10*time.Millisecond)
+	}()
+
+	writeFile(t, path, "c:\n  limit: 30\n")
+
+	select {
+	case limit := <-reloaded:
+		if limit != 30 {
+			t.Errorf("reloaded limit = %d, want: %d", limit, 30)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no reloading after the config file changes")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("set.Watch() = %v, want: %v", err, context.Canceled)
+	}
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := set.Watch(ctx, interval); err == nil {
+			t.Errorf("set.Watch(%v) returns no error, want an error", interval)
+		}
+	}
+}
+
+func TestReloadWithReaders(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n  tags: [a]\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				set.RLock()
+				limit, tags, pointer := cfg.Limit, cfg.Tags, *cfg.Pointer
+				set.RUnlock()
+
+				// limit, tags and pointer are updated together.
+				if want := []string{fmt.Sprint(limit)}; limit != 20 && (!slices.Equal(tags, want) || pointer != limit) {
+					t.Errorf("read a half-updated config: limit = %d, tags = %v, pointer = %d", limit, tags, pointer)
+				}
+			}
+		}()
+	}
+
+	for i := 100; i < 110; i++ {
+		writeFile(t, path, fmt.Sprintf("c:\n  limit: %[1]d\n  tags: [\"%[1]d\"]\n  pointer: %[1]d\n", i))
+		if err := set.Reload(ctx); err != nil {
+			t.Fatalf("set.Reload() error: %v", err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+type reloadUnexportedConfig struct {
+	Tags   []string       `clic:"tags"`
+	Limits map[string]int `clic:"limits"`
+	Inner  struct {
+		Hosts []string `clic:"hosts"`
+	} `clic:"inner"`
+
+	note string
+}
+
+func TestReloadUnexportedField(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  tags: [a]\n  limits:\n    a: 1\n  inner:\n    hosts: [x]\n")
+
+	cfg := reloadUnexportedConfig{note: "keep"}
+	set.RegisterValue("c", &cfg)
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	want := reloadUnexportedConfig{Tags: []string{"a"}, Limits: map[string]int{"a": 1}, note: "keep"}
+	want.Inner.Hosts = []string{"x"}
+	opt := cmp.AllowUnexported(reloadUnexportedConfig{})
+	if diff := cmp.Diff(cfg, want, opt); diff != "" {
+		t.Fatalf("after set.Parse(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	// a failed reload must not change the last good config, even partly.
+	writeFile(t, path, "c:\n  tags: [b]\n  limits:\n    b: 2\n  inner:\n    hosts: [y]\n  unknown: [")
+	if err := set.Reload(ctx); err == nil {
+		t.Fatalf("set.Reload() with an invalid file returns no error, want an error")
+	}
+	if diff := cmp.Diff(cfg, want, opt); diff != "" {
+		t.Errorf("after a failed set.Reload(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	// the baseline keeps defaults, so removed keys go back to defaults.
+	writeFile(t, path, "c:\n  tags: [c]\n")
+	if err := set.Reload(ctx); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+	want = reloadUnexportedConfig{Tags: []string{"c"}, note: "keep"}
+	if diff := cmp.Diff(cfg, want, opt); diff != "" {
+		t.Errorf("after set.Reload(), config diff: (-got, +want)\n%s", diff)
+	}
+
+	// values users read share nothing with the values which sources parse into.
+	cfg.Tags[0] = "changed"
+	writeFile(t, path, "c:\n  limits:\n    d: 4\n")
+	if err := set.Reload(ctx); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+	want = reloadUnexportedConfig{Limits: map[string]int{"d": 4}, note: "keep"}
+	if diff := cmp.Diff(cfg, want, opt); diff != "" {
+		t.Errorf("after set.Reload(), config diff: (-got, +want)\n%s", diff)
+	}
+}
+
+func TestCallbacksReadSet(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n")
+
+	var cfg reloadConfig
+	set.RegisterValue("c", &cfg)
+
+	// readSet calls accessors which parsing also locks, and fails if they block.
+	var reads int
+	readSet := func(ctx context.Context) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = set.Provenance()
+			_ = set.Selected()
+			_ = set.WriteConfig(io.Discard)
+		}()
+
+		select {
+		case <-done:
+			reads++
+		case <-time.After(time.Second):
+			t.Error("reading the set in a callback blocks")
+		}
+	}
+	set.RegisterCallback("cb", func(ctx context.Context, _ *struct{}) error {
+		readSet(ctx)
+		return nil
+	})
+	set.OnReload("c", func(ctx context.Context, _, _ *reloadConfig) {
+		readSet(ctx)
+	})
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	writeFile(t, path, "c:\n  limit: 30\n")
+	if err := set.Reload(ctx); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+
+	if reads != 2 {
+		t.Errorf("callbacks read the set %d times, want 2", reads)
+	}
+}
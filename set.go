@@ -3,6 +3,12 @@ package clic
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/googollee/clic/source"
 	"github.com/googollee/clic/structtags"
@@ -15,10 +21,35 @@ var DefaultSources = []source.Source{
 }
 
 type Set struct {
-	fset    source.FlagSet
-	sources []source.Source
-	configs map[string]*config
-	fields  []structtags.Field
+	fset     source.FlagSet
+	sources  []source.Source
+	configs  map[string]*config
+	prefixes []string
+	depends  map[string][]string
+	fields   []structtags.Field
+	keys     []map[structtags.Origin][]string // keys are names of fields which own keys of each source, indexed like sources.
+
+	printFlag     string
+	printConfig   bool
+	templateFlag  string
+	printTemplate bool
+
+	// parseMu guards parsing, and fields with staging values.
+	parseMu     sync.Mutex
+	parsed      bool
+	args        []string
+	baseOrigins []structtags.Origin
+
+	// valueMu guards registered values which users read.
+	valueMu sync.RWMutex
+
+	parent       *Set
+	name         string
+	description  string
+	commands     map[string]*Set
+	commandNames []string
+	selected     []string // selected are names of commands selected by args, which are merged into the root set.
+	positional   []string // positional are args left by flags of the last selected command.
 }
 
 func NewSet(fset source.FlagSet, source ...source.Source) *Set {
@@ -30,9 +61,14 @@ func NewSet(fset source.FlagSet, source ...source.Source) *Set {
 		fset:    fset,
 		sources: source,
 		configs: make(map[string]*config),
+		depends: make(map[string][]string),
+		keys:    make([]map[structtags.Origin][]string, len(source)),
 	}
 }
 
+// RegisterValue registers a pointer to a config value with the "prefix" as the scope name. The value is filled after calling [Set.Parse].
+//
+// The value is updated in place by [Set.Reload]. Other goroutines reading the value while reloading should hold [Set.RLock].
 func (s *Set) RegisterValue(prefix string, value any) {
 	if err := s.register(prefix, newConfigValue(value)); err != nil {
 		panic(err)
@@ -45,48 +81,297 @@ func (s *Set) RegisterCallback(prefix string, callback any) {
 	}
 }
 
+// DependOn declares that the callback of the "prefix" scope is called after callbacks of the "depends" scopes.
+//
+// Without dependencies, callbacks are called in the order of registration.
+func (s *Set) DependOn(prefix string, depends ...string) {
+	s.depends[prefix] = append(s.depends[prefix], depends...)
+}
+
+// Parse parses `args` and all sources, and calls callbacks of registered scopes.
+// It reports all missing and invalid fields in one [*ValidationError].
 func (s *Set) Parse(ctx context.Context, args []string) error {
-	for i := range len(s.sources) {
-		src := s.sources[i]
-		if err := src.Register(s.fset, s.fields); err != nil {
-			return fmt.Errorf("prepare source %T error: %w", src, err)
+	prefixes, err := s.parse(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	// Callbacks are called without holding parseMu, so they could read the set, like [Set.Provenance].
+	for _, prefix := range prefixes {
+		if err := s.configs[prefix].Callback(ctx); err != nil {
+			return fmt.Errorf("init config %q error: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
+
+// parse parses flags and sources, publishes values, and returns prefixes in the order of calling callbacks.
+func (s *Set) parse(ctx context.Context, args []string) ([]string, error) {
+	s.parseMu.Lock()
+	defer s.parseMu.Unlock()
+
+	if s.parent != nil {
+		return nil, fmt.Errorf("parse command %q, which should be parsed by the root set", s.fullName())
+	}
+
+	args, err := s.parseFlags(args)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixes, err := s.sortedPrefixes()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.printTemplate {
+		if err := s.WriteTemplate(os.Stdout); err != nil {
+			return nil, fmt.Errorf("print config template error: %w", err)
 		}
+		return nil, source.ErrQuitEarly
 	}
 
-	if s.fset != nil && !s.fset.Parsed() {
-		if err := s.fset.Parse(args); err != nil {
-			return err
+	if err := s.parseSources(ctx, args); err != nil {
+		return nil, err
+	}
+	s.publish()
+	s.parsed = true
+	s.args = args
+
+	if s.printConfig {
+		if err := s.writeConfig(os.Stdout); err != nil {
+			return nil, fmt.Errorf("print config error: %w", err)
 		}
+		return nil, source.ErrQuitEarly
 	}
 
+	return prefixes, nil
+}
+
+// parseSources parses all sources into staging values in the order of precedence, and validates all fields.
+func (s *Set) parseSources(ctx context.Context, args []string) error {
+	var fieldErrs []*source.FieldError
 	for i := len(s.sources) - 1; i >= 0; i-- {
 		src := s.sources[i]
 		if err := src.Parse(ctx, args); err != nil {
-			return fmt.Errorf("parse config from source %T error: %w", src, err)
+			errs, ok := fieldErrors(err)
+			if !ok {
+				return fmt.Errorf("parse config from source %T error: %w", src, err)
+			}
+			fieldErrs = append(fieldErrs, errs...)
 		}
 	}
 
-	for name, handler := range s.configs {
-		if err := handler.Callback(ctx); err != nil {
-			return fmt.Errorf("init config %q error: %w", name, err)
-		}
+	errs, err := s.parseArgs()
+	if err != nil {
+		return err
 	}
+	fieldErrs = append(fieldErrs, errs...)
 
-	return nil
+	s.warnDeprecated(ctx)
+
+	return s.validate(fieldErrs)
+}
+
+// publish copies staging values to values which users read.
+func (s *Set) publish() {
+	s.valueMu.Lock()
+	defer s.valueMu.Unlock()
+
+	for _, prefix := range s.prefixes {
+		config := s.configs[prefix]
+		copyValue(config.value.Elem(), config.staging.Elem())
+		config.snapshot.Store(cloneValue(config.value).Interface())
+	}
 }
 
 func (s *Set) register(prefix string, config *config) error {
-	fields, err := structtags.ParseStruct(config.Value(), []string{prefix})
+	config.staging = cloneValue(config.Value())
+	fields, err := structtags.ParseStruct(config.staging, []string{prefix})
 	if err != nil {
 		return err
 	}
+	config.baseline = cloneValue(config.staging)
+	config.snapshot.Store(cloneValue(config.baseline).Interface())
 
 	if _, exist := s.configs[prefix]; exist {
 		return fmt.Errorf("already registered a config with prefix %s", prefix)
 	}
+	for parent := s.parent; parent != nil; parent = parent.parent {
+		if _, exist := parent.configs[prefix]; exist {
+			return fmt.Errorf("already registered a config with prefix %s in command %q", prefix, parent.fullName())
+		}
+	}
+
+	if len(s.commands) > 0 && hasArgs(fields) {
+		return fmt.Errorf("config %s has positional arguments, but %q has commands", prefix, s.fullName())
+	}
+	if _, err := argFields(append(s.scopeFields(), fields...)); err != nil {
+		return err
+	}
+
+	if err := s.indexKeys(fields); err != nil {
+		return err
+	}
 
 	s.fields = append(s.fields, fields...)
+	for _, field := range fields {
+		s.baseOrigins = append(s.baseOrigins, field.Origin())
+	}
 	s.configs[prefix] = config
+	s.prefixes = append(s.prefixes, prefix)
 
 	return nil
 }
+
+// sortedPrefixes returns prefixes in the order of registration, and moves a prefix after its dependencies.
+func (s *Set) sortedPrefixes() ([]string, error) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	states := make(map[string]int, len(s.prefixes))
+	ret := make([]string, 0, len(s.prefixes))
+
+	var visit func(prefix string, path []string) error
+	visit = func(prefix string, path []string) error {
+		path = append(path, prefix)
+
+		switch states[prefix] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle between configs: %s", strings.Join(path, " -> "))
+		}
+
+		if _, ok := s.configs[prefix]; !ok {
+			return fmt.Errorf("config %q depends on config %q, which is not registered", path[len(path)-2], prefix)
+		}
+
+		states[prefix] = visiting
+		for _, depend := range s.depends[prefix] {
+			if err := visit(depend, path); err != nil {
+				return err
+			}
+		}
+		states[prefix] = visited
+
+		ret = append(ret, prefix)
+		return nil
+	}
+
+	for _, prefix := range s.prefixes {
+		if err := visit(prefix, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, prefix := range slices.Sorted(maps.Keys(s.depends)) {
+		if _, ok := s.configs[prefix]; !ok {
+			return nil, fmt.Errorf("config %q has dependencies, but is not registered", prefix)
+		}
+	}
+
+	return ret, nil
+}
+
+// warnDeprecated logs a warning for each deprecated field set by a source.
+func (s *Set) warnDeprecated(ctx context.Context) {
+	for _, field := range s.fields {
+		origin := field.Origin()
+		if field.Deprecated == "" || origin.Source == "" || origin.Source == "default" {
+			continue
+		}
+
+		slog.WarnContext(ctx, "config field is deprecated", "field", strings.Join(field.Name, "."), "from", origin.String(), "message", field.Deprecated)
+	}
+}
+
+// scopeFields returns fields of the set and its parent commands.
+func (s *Set) scopeFields() []structtags.Field {
+	var ret []structtags.Field
+	for parent := s.parent; parent != nil; parent = parent.parent {
+		ret = append(slices.Clip(parent.fields), ret...)
+	}
+
+	return append(ret, s.fields...)
+}
+
+// indexKeys adds keys of `fields` in each source to the key index of the set.
+// It returns an error and adds nothing if any key conflicts with a key of another field in the same source, including fields of parent commands,
+// or a field uses the name of a flag registered by the set or its sources.
+func (s *Set) indexKeys(fields []structtags.Field) error {
+	reserved := s.reservedFlags()
+	index := make([]map[structtags.Origin][]string, len(s.sources))
+
+	for i, src := range s.sources {
+		keyer, ok := src.(source.Keyer)
+		if !ok {
+			continue
+		}
+
+		index[i] = make(map[structtags.Origin][]string)
+		for _, field := range fields {
+			for _, key := range keyer.Keys(field) {
+				if slices.Contains(reserved, key) {
+					return fmt.Errorf("%s of field %v conflicts with a built-in flag", key, field.Name)
+				}
+				if owner, exist := index[i][key]; exist {
+					return fmt.Errorf("%s of field %v conflicts with field %v", key, field.Name, owner)
+				}
+				if owner, exist := s.keyOwner(i, key); exist {
+					return fmt.Errorf("%s of field %v conflicts with field %v", key, field.Name, owner)
+				}
+				index[i][key] = field.Name
+			}
+		}
+	}
+
+	for i, keys := range index {
+		if keys == nil {
+			continue
+		}
+		if s.keys[i] == nil {
+			s.keys[i] = make(map[structtags.Origin][]string, len(keys))
+		}
+		maps.Copy(s.keys[i], keys)
+	}
+
+	return nil
+}
+
+// keyOwner returns the name of the field which owns `key` of the source at index `i`, in the set or its parent commands.
+func (s *Set) keyOwner(i int, key structtags.Origin) ([]string, bool) {
+	for set := s; set != nil; set = set.parent {
+		if owner, exist := set.keys[i][key]; exist {
+			return owner, true
+		}
+	}
+
+	return nil, false
+}
+
+// reservedFlags returns origins of flags registered by the root set and sources for themselves, which fields can't use.
+func (s *Set) reservedFlags() []structtags.Origin {
+	root := s.root()
+	var names []string
+	if root.printFlag != "" {
+		names = append(names, root.printFlag)
+	}
+	if root.templateFlag != "" {
+		names = append(names, root.templateFlag)
+	}
+	for _, src := range s.sources {
+		if owner, ok := src.(source.FlagOwner); ok {
+			names = append(names, owner.Flags()...)
+		}
+	}
+
+	ret := make([]structtags.Origin, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, source.FlagKey(name))
+	}
+
+	return ret
+}
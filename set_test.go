@@ -8,10 +8,14 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"unsafe"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/googollee/clic"
 	"github.com/googollee/clic/source"
 )
@@ -104,8 +108,8 @@ func ExampleSet_showHelp() {
 	fmt.Println(strings.ReplaceAll(helpOutput.String(), "\t", "    "))
 	// Output:
 	// Usage:
-	//   -config string
-	//         the path of the config file
+	//   -config value
+	//         the paths of config files, separated by ","
 	//   -database.driver value
 	//         the driver of the database [sqlite3,mysql,postgres] (default sqlite3)
 	//   -database.url value
@@ -165,6 +169,106 @@ func TestInvalidRegister(t *testing.T) {
 	}
 }
 
+func TestRegisterKeyConflict(t *testing.T) {
+	type URL struct {
+		URL string `clic:"url" env:"DATABASE_URL" flag:"url,u"`
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		value  any
+	}{
+		{"SameEnv", "other", &struct {
+			URL string `clic:"url" env:"DB_URL,DATABASE_URL"`
+		}{}},
+		{"SameFlag", "other", &struct {
+			URL string `clic:"url" flag:"u"`
+		}{}},
+		{"SameShort", "other", &struct {
+			URL string `clic:"url" short:"url"`
+		}{}},
+		{"GeneratedEnv", "DATABASE", &struct {
+			URL string `clic:"URL"`
+		}{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			set := clic.NewSet(fset)
+
+			set.RegisterValue("database", &URL{})
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("set.Register() passes, want a panic")
+				}
+			}()
+
+			set.RegisterValue(tc.prefix, tc.value)
+		})
+	}
+}
+
+func TestRegisterBuiltinFlagConflict(t *testing.T) {
+	type Config struct {
+		Value string `clic:"value" flag:"config"`
+	}
+	type Print struct {
+		Value bool `clic:"value" flag:"print-config"`
+	}
+	type Dir struct {
+		Value string `clic:"value" flag:"config-dir"`
+	}
+
+	tests := []struct {
+		name     string
+		register func(set *clic.Set)
+	}{
+		{"FilePathFlag", func(set *clic.Set) {
+			set.RegisterValue("c", &Config{})
+		}},
+		{"FileDirFlag", func(set *clic.Set) {
+			set.RegisterValue("c", &Dir{})
+		}},
+		{"PrintConfigFlag", func(set *clic.Set) {
+			set.PrintConfigFlag("print-config")
+			set.RegisterValue("c", &Print{})
+		}},
+		{"PrintTemplateFlag", func(set *clic.Set) {
+			set.PrintTemplateFlag("print-config")
+			set.RegisterValue("c", &Print{})
+		}},
+		{"PrintFlagAfterField", func(set *clic.Set) {
+			set.RegisterValue("c", &Print{})
+			set.PrintConfigFlag("print-config")
+		}},
+		{"Command", func(set *clic.Set) {
+			set.Command("run", "").RegisterValue("c", &Config{})
+		}},
+		{"PrintFlagAfterCommandField", func(set *clic.Set) {
+			set.Command("run", "").RegisterValue("c", &Print{})
+			set.PrintConfigFlag("print-config")
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			set := clic.NewSet(fset, source.Flag(), source.File(source.FileDirFlag("config-dir")))
+
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("registering passes, want a panic")
+				}
+			}()
+
+			tc.register(set)
+		})
+	}
+}
+
 func TestInvalidRegisterCallback(t *testing.T) {
 	type C struct{ Int int }
 	var c C
@@ -247,3 +351,260 @@ func TestInvalidValue(t *testing.T) {
 		t.Errorf("set.Parse() = %v, want an error", err)
 	}
 }
+
+func TestCallbackOrder(t *testing.T) {
+	type C struct{}
+
+	tests := []struct {
+		name      string
+		depends   map[string][]string
+		wantOrder []string
+		wantOk    bool
+	}{
+		{"RegisterOrder", nil, []string{"a", "b", "c", "d"}, true},
+		{"DependOn", map[string][]string{"a": {"c"}}, []string{"c", "a", "b", "d"}, true},
+		{"DependOnChain", map[string][]string{"a": {"b"}, "b": {"d"}}, []string{"d", "b", "a", "c"}, true},
+		{"DependOnMulti", map[string][]string{"a": {"d", "c"}}, []string{"d", "c", "a", "b"}, true},
+		{"Cycle", map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}}, nil, false},
+		{"SelfCycle", map[string][]string{"a": {"a"}}, nil, false},
+		{"UnknownDepend", map[string][]string{"a": {"x"}}, nil, false},
+		{"UnknownPrefix", map[string][]string{"x": {"a"}}, nil, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			set := clic.NewSet(fset)
+
+			var order []string
+			for _, prefix := range []string{"a", "b", "c", "d"} {
+				set.RegisterCallback(prefix, func(context.Context, *C) error {
+					order = append(order, prefix)
+					return nil
+				})
+			}
+
+			for prefix, depends := range tc.depends {
+				set.DependOn(prefix, depends...)
+			}
+
+			err := set.Parse(t.Context(), []string{})
+			if gotOk := err == nil; gotOk != tc.wantOk {
+				t.Fatalf("set.Parse() = %v, want ok: %v", err, tc.wantOk)
+			}
+
+			if diff := cmp.Diff(order, tc.wantOrder); diff != "" {
+				t.Errorf("callback order diff: (-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnknownPrefixError(t *testing.T) {
+	type C struct{}
+
+	// The error should be the same each time, no matter how maps are iterated.
+	for range 20 {
+		set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError))
+		set.RegisterCallback("a", func(context.Context, *C) error { return nil })
+		for _, prefix := range []string{"z", "y", "x", "w"} {
+			set.DependOn(prefix, "a")
+		}
+
+		err := set.Parse(t.Context(), []string{})
+		if want := `config "w" has dependencies, but is not registered`; err == nil || err.Error() != want {
+			t.Fatalf("set.Parse() = %v, want: %s", err, want)
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	type C struct {
+		Required        string `clic:"required" required:"true"`
+		RequiredDefault string `clic:"required_default,default" required:"true"`
+		Int             int    `clic:"int"`
+		Port            int    `clic:"port,80" min:"1" max:"65535"`
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		envs       map[string]string
+		wantFields []string
+		wantErrs   []error
+	}{
+		{"Valid", []string{"-c.required", ""}, nil, nil, nil},
+		{"ValidFromEnv", nil, map[string]string{"C_REQUIRED": "value"}, nil, nil},
+		{"Missing", nil, nil, []string{"c.required"}, []error{clic.ErrRequired}},
+		{"InvalidAndMissing", nil, map[string]string{"C_INT": "abc"}, []string{"c.int", "c.required"}, []error{strconv.ErrSyntax, clic.ErrRequired}},
+		{"Constraint", []string{"-c.required", "", "-c.port", "0"}, nil, []string{"c.port"}, []error{nil}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			set := clic.NewSet(fset)
+
+			var c C
+			set.RegisterValue("c", &c)
+
+			for key, value := range tc.envs {
+				t.Setenv(key, value)
+			}
+
+			err := set.Parse(t.Context(), tc.args)
+			if len(tc.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("set.Parse() = %v, want no error", err)
+				}
+				return
+			}
+
+			var validationErr *clic.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("set.Parse() = %v, want a *clic.ValidationError", err)
+			}
+
+			var gotFields []string
+			for i, fieldErr := range validationErr.Errors {
+				gotFields = append(gotFields, strings.Join(fieldErr.Field, "."))
+
+				if tc.wantErrs[i] != nil && !errors.Is(fieldErr, tc.wantErrs[i]) {
+					t.Errorf("field error %v, want: %v", fieldErr, tc.wantErrs[i])
+				}
+
+				var gotKeys []string
+				for _, key := range fieldErr.Keys {
+					gotKeys = append(gotKeys, key.String())
+				}
+				wantKeys := []string{"flag -" + gotFields[i], "file " + gotFields[i], "env " + strings.ToUpper(strings.ReplaceAll(gotFields[i], ".", "_"))}
+				if diff := cmp.Diff(gotKeys, wantKeys); diff != "" {
+					t.Errorf("keys of field %s diff: (-got, +want)\n%s", gotFields[i], diff)
+				}
+			}
+
+			if diff := cmp.Diff(gotFields, tc.wantFields); diff != "" {
+				t.Errorf("invalid fields diff: (-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidationWithFile(t *testing.T) {
+	type C struct {
+		Limit int    `clic:"limit"`
+		Port  int    `clic:"port"`
+		Count int    `clic:"count"`
+		Name  string `clic:"name" required:"true"`
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("c:\n  limit: abc\n  port: xyz\n"), 0o600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	t.Setenv("C_COUNT", "abc")
+
+	set := clic.NewSet(flag.NewFlagSet("", flag.ContinueOnError), source.Flag(), source.File(source.FileFormat(source.YAML{})), source.Env())
+
+	var c C
+	set.RegisterValue("c", &c)
+
+	err := set.Parse(t.Context(), []string{"-config", path})
+	var validationErr *clic.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("set.Parse() = %v, want a *clic.ValidationError", err)
+	}
+
+	got := make(map[string]string)
+	for _, fieldErr := range validationErr.Errors {
+		got[strings.Join(fieldErr.Field, ".")] = fieldErr.Origin.String()
+	}
+	want := map[string]string{
+		"c.limit": "file c.limit in " + path + ":2",
+		"c.port":  "file c.port in " + path + ":3",
+		"c.count": "env C_COUNT",
+		"c.name":  "",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("invalid fields diff: (-got, +want)\n%s", diff)
+	}
+}
+
+func TestInvalidDefault(t *testing.T) {
+	type C struct {
+		Port  int `clic:"port,0" min:"1"`
+		Count int `clic:"count" min:"1"`
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	set := clic.NewSet(fset)
+
+	var c C
+	set.RegisterValue("c", &c)
+
+	err := set.Parse(t.Context(), []string{})
+	var validationErr *clic.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("set.Parse() = %v, want a *clic.ValidationError", err)
+	}
+
+	// The default value is checked, and a field without any value is not.
+	if got := len(validationErr.Errors); got != 1 {
+		t.Fatalf("set.Parse() returns %d field errors, want 1: %v", got, err)
+	}
+	fieldErr := validationErr.Errors[0]
+	if diff := cmp.Diff(fieldErr.Field, []string{"c", "port"}); diff != "" {
+		t.Errorf("invalid field diff: (-got, +want)\n%s", diff)
+	}
+	if got, want := fieldErr.Origin.Source, "default"; got != want {
+		t.Errorf("origin of the invalid field = %q, want: %q", got, want)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	type C struct {
+		Password string `clic:"password,default_pass" secret:"true" minlen:"20"`
+		Pin      int    `clic:"pin" secret:"true"`
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		envs   map[string]string
+		secret string
+	}{
+		{"InvalidFlag", []string{"-c.pin", "p4ss"}, nil, "p4ss"},
+		{"InvalidEnv", nil, map[string]string{"C_PIN": "p4ss"}, "p4ss"},
+		{"Constraint", []string{"-c.password", "short_pass"}, nil, "short_pass"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var output strings.Builder
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			fset.SetOutput(&output)
+			set := clic.NewSet(fset)
+
+			var c C
+			set.RegisterValue("c", &c)
+
+			for key, value := range tc.envs {
+				t.Setenv(key, value)
+			}
+
+			err := set.Parse(t.Context(), tc.args)
+			if err == nil {
+				t.Fatalf("set.Parse() returns no error, want an error")
+			}
+
+			if msg := err.Error(); strings.Contains(msg, tc.secret) {
+				t.Errorf("set.Parse() = %q, which contains the secret %q", msg, tc.secret)
+			}
+
+			fset.PrintDefaults()
+			if got := output.String(); strings.Contains(got, tc.secret) || strings.Contains(got, "default_pass") {
+				t.Errorf("output %q contains the secret", got)
+			}
+		})
+	}
+}
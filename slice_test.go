@@ -0,0 +1,63 @@
+package clic_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/googollee/clic"
+)
+
+func Example_sliceField() {
+	// prepare env
+	if err := os.Setenv("KAFKA_TOPICS", "orders;payments"); err != nil {
+		log.Fatal("set env error:", err)
+	}
+
+	// prepare config file
+	cfgFile, err := os.CreateTemp("", "config_*.json")
+	if err != nil {
+		log.Fatal("create temp file error:", err)
+	}
+	defer os.Remove(cfgFile.Name())
+
+	if _, err := cfgFile.WriteString(`{"kafka": {"partitions": [1, 2, 3]}}`); err != nil {
+		log.Fatal("write temp file error:", err)
+	}
+
+	if err := cfgFile.Close(); err != nil {
+		log.Fatal("close temp file error:", err)
+	}
+
+	// prepare args
+	args := []string{"-config", cfgFile.Name(), "-kafka.brokers", "localhost:9092", "-kafka.brokers", "localhost:9093"}
+
+	// code starts
+	type Kafka struct {
+		Brokers    []string `clic:"brokers,,the addresses of brokers"`
+		Topics     []string `clic:"topics,,the topics to consume" sep:";"`
+		Partitions []int    `clic:"partitions,0,the partitions to consume"`
+	}
+
+	fset := flag.NewFlagSet("", flag.PanicOnError)
+	set := clic.NewSet(fset)
+
+	var cfg Kafka
+	set.RegisterValue("kafka", &cfg)
+
+	ctx := context.Background()
+	if err := set.Parse(ctx, args); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	fmt.Printf("Brokers: %q\n", cfg.Brokers)
+	fmt.Printf("Topics: %q\n", cfg.Topics)
+	fmt.Println("Partitions:", cfg.Partitions)
+
+	// Output:
+	// Brokers: ["localhost:9092" "localhost:9093"]
+	// Topics: ["orders" "payments"]
+	// Partitions: [1 2 3]
+}
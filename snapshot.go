@@ -0,0 +1,63 @@
+package clic
+
+import (
+	"context"
+	"fmt"
+)
+
+// pinKey is the key of pinned snapshots of a [Set] in a context.
+type pinKey struct {
+	set *Set
+}
+
+/*
+RegisterSetType registers a "Config" type with the "name" as the scope name in the set "s", and returns a getter function.
+
+The getter returns an immutable snapshot of the config from the latest successful [Set.Parse] or [Set.Reload], or default values before parsing.
+A reload replaces the snapshot atomically, so a reader never sees a half-updated value. The snapshot is shared by all readers and must not be modified.
+
+If "ctx" is returned by [Set.Pin], the getter returns the snapshot pinned in "ctx" instead.
+*/
+func RegisterSetType[Config any](s *Set, name string) func(ctx context.Context) *Config {
+	var c Config
+	s.RegisterValue(name, &c)
+
+	return func(ctx context.Context) *Config {
+		return s.snapshot(ctx, name).(*Config)
+	}
+}
+
+// Pin returns a child context of `ctx` with current snapshots of all registered scopes.
+// Getters returned by [RegisterSetType] return snapshots pinned in the context, even if the config is reloaded later.
+// It's useful to read a consistent config in a whole request.
+func (s *Set) Pin(ctx context.Context) context.Context {
+	s = s.root()
+
+	s.valueMu.RLock()
+	defer s.valueMu.RUnlock()
+
+	snapshots := make(map[string]any, len(s.prefixes))
+	for _, prefix := range s.prefixes {
+		snapshots[prefix] = s.configs[prefix].snapshot.Load()
+	}
+
+	return context.WithValue(ctx, pinKey{set: s}, snapshots)
+}
+
+// snapshot returns the snapshot of the `prefix` scope pinned in `ctx`, or the latest one.
+func (s *Set) snapshot(ctx context.Context, prefix string) any {
+	if ctx != nil {
+		if snapshots, ok := ctx.Value(pinKey{set: s.root()}).(map[string]any); ok {
+			if ret, ok := snapshots[prefix]; ok {
+				return ret
+			}
+		}
+	}
+
+	config, ok := s.configs[prefix]
+	if !ok {
+		panic(fmt.Sprintf("get snapshot of config %q, which is not registered", prefix))
+	}
+
+	return config.snapshot.Load()
+}
@@ -0,0 +1,171 @@
+package clic_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic"
+)
+
+func TestRegisterSetType(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n  tags: [a]\n")
+	get := clic.RegisterSetType[reloadConfig](set, "c")
+
+	ctx := t.Context()
+	one := 1
+
+	defaults := get(ctx)
+	if diff := cmp.Diff(*defaults, reloadConfig{Level: "info", Limit: 10, Pointer: &one}); diff != "" {
+		t.Errorf("before set.Parse(), getter diff: (-got, +want)\n%s", diff)
+	}
+
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	parsed := get(ctx)
+	want := reloadConfig{Level: "info", Limit: 20, Pointer: &one, Tags: []string{"a"}}
+	if diff := cmp.Diff(*parsed, want); diff != "" {
+		t.Errorf("after set.Parse(), getter diff: (-got, +want)\n%s", diff)
+	}
+	if get(nil) != parsed {
+		t.Errorf("getter returns different snapshots without reloading")
+	}
+
+	pinned := set.Pin(ctx)
+
+	writeFile(t, path, "c:\n  limit: 30\n  pointer: 2\n")
+	if err := set.Reload(ctx); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+
+	two := 2
+	newWant := reloadConfig{Level: "info", Limit: 30, Pointer: &two}
+	if diff := cmp.Diff(*get(ctx), newWant); diff != "" {
+		t.Errorf("after set.Reload(), getter diff: (-got, +want)\n%s", diff)
+	}
+	if diff := cmp.Diff(*parsed, want); diff != "" {
+		t.Errorf("after set.Reload(), the old snapshot changes: (-got, +want)\n%s", diff)
+	}
+	if got := get(pinned); got != parsed {
+		t.Errorf("after set.Reload(), getter with the pinned context = %+v, want: %+v", got, parsed)
+	}
+
+	writeFile(t, path, "c:\n  limit: abc\n")
+	if err := set.Reload(ctx); err == nil {
+		t.Fatalf("set.Reload() with an invalid file returns no error, want an error")
+	}
+	if diff := cmp.Diff(*get(ctx), newWant); diff != "" {
+		t.Errorf("after a failed set.Reload(), getter diff: (-got, +want)\n%s", diff)
+	}
+}
+
+func TestPinOtherSet(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n")
+	get := clic.RegisterSetType[reloadConfig](set, "c")
+
+	other, _ := newReloadSet(t, "")
+	clic.RegisterSetType[reloadConfig](other, "c")
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	if got, want := get(other.Pin(ctx)).Limit, 20; got != want {
+		t.Errorf("getter with a context pinned by another set, limit = %d, want: %d", got, want)
+	}
+}
+
+func TestSnapshotWithReaders(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  limit: 20\n  tags: [\"20\"]\n  pointer: 20\n")
+	get := clic.RegisterSetType[reloadConfig](set, "c")
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				cfg := get(context.Background())
+				if tag := fmt.Sprint(cfg.Limit); len(cfg.Tags) != 1 || cfg.Tags[0] != tag || *cfg.Pointer != cfg.Limit {
+					t.Errorf("read a half-updated config: %+v", cfg)
+				}
+			}
+		}()
+	}
+
+	for i := 100; i < 110; i++ {
+		writeFile(t, path, fmt.Sprintf("c:\n  limit: %[1]d\n  tags: [\"%[1]d\"]\n  pointer: %[1]d\n", i))
+		if err := set.Reload(ctx); err != nil {
+			t.Fatalf("set.Reload() error: %v", err)
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
+
+func TestSnapshotIsolation(t *testing.T) {
+	set, path := newReloadSet(t, "c:\n  tags: [a]\n  limits:\n    a: 1\n  inner:\n    hosts: [x]\n")
+	get := clic.RegisterSetType[reloadUnexportedConfig](set, "c")
+
+	ctx := t.Context()
+	if err := set.Parse(ctx, []string{"-config", path}); err != nil {
+		t.Fatalf("set.Parse() error: %v", err)
+	}
+
+	snapshot := get(ctx)
+	pinned := set.Pin(ctx)
+
+	want := reloadUnexportedConfig{Tags: []string{"a"}, Limits: map[string]int{"a": 1}}
+	want.Inner.Hosts = []string{"x"}
+	opt := cmp.AllowUnexported(reloadUnexportedConfig{})
+
+	for _, content := range []string{
+		"c:\n  tags: [b]\n  limits:\n    a: 2\n    b: 2\n  inner:\n    hosts: [y]\n",
+		"c:\n  tags: [c]\n  limits:\n    a: 3\n  inner:\n    hosts: [z]\n  unknown: [",
+	} {
+		writeFile(t, path, content)
+		_ = set.Reload(ctx)
+
+		if diff := cmp.Diff(*snapshot, want, opt); diff != "" {
+			t.Errorf("after reloading with %q, the old snapshot changes: (-got, +want)\n%s", content, diff)
+		}
+		if got := get(pinned); got != snapshot {
+			t.Errorf("after reloading with %q, getter with the pinned context = %+v, want: %+v", content, got, snapshot)
+		}
+	}
+
+	latest := get(ctx)
+	want = reloadUnexportedConfig{Tags: []string{"b"}, Limits: map[string]int{"a": 2, "b": 2}}
+	want.Inner.Hosts = []string{"y"}
+	if diff := cmp.Diff(*latest, want, opt); diff != "" {
+		t.Errorf("getter diff: (-got, +want)\n%s", diff)
+	}
+
+	// the next reload starts from values which share nothing with snapshots.
+	writeFile(t, path, "c:\n  limits:\n    c: 3\n")
+	if err := set.Reload(ctx); err != nil {
+		t.Fatalf("set.Reload() error: %v", err)
+	}
+	if diff := cmp.Diff(*latest, want, opt); diff != "" {
+		t.Errorf("after set.Reload(), the old snapshot changes: (-got, +want)\n%s", diff)
+	}
+}
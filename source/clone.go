@@ -21,7 +21,7 @@ func newFromFields(fields []structtags.Field, index int, tagFmt string) reflect.
 		if end == 1 && index == len(fields[0].Name)-1 {
 			wantValues = append(wantValues, NamedValue{
 				name:  fields[0].Name[index],
-				value: reflect.ValueOf(fields[0]),
+				value: reflect.ValueOf(fileField{Field: fields[0], err: new(*FieldError)}),
 			})
 			fields = fields[1:]
 			continue
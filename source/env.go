@@ -2,6 +2,7 @@ package source
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -21,10 +22,47 @@ func EnvSplitter(splitter string) EnvOption {
 	}
 }
 
+// EnvPrefix adds the "prefix" and the splitter before generated env keys, like "MYAPP_DATABASE_HOST" with the "myapp" prefix.
+// Keys declared by the `env` tag of a field are not changed.
+func EnvPrefix(prefix string) EnvOption {
+	return func(s *envSource) error {
+		if prefix == "" {
+			return fmt.Errorf("invalid env prefix: %q", prefix)
+		}
+		s.prefix = strings.ToUpper(prefix)
+		return nil
+	}
+}
+
+// EnvBareFallback reads generated env keys without the prefix too, like "DATABASE_HOST" besides "MYAPP_DATABASE_HOST".
+// If both are set, the prefixed one wins. It only works with [EnvPrefix].
+func EnvBareFallback() EnvOption {
+	return func(s *envSource) error {
+		s.bareFallback = true
+		return nil
+	}
+}
+
+// EnvFiles reads the value of a field from a file if the env key with the "_FILE" suffix is set, like "DATABASE_PASSWORD_FILE=/run/secrets/db".
+// The key without the suffix wins if both are set. The trailing newline of the file is trimmed.
+// A field could enable it by the `envfile:"true"` tag without this option.
+func EnvFiles() EnvOption {
+	return func(s *envSource) error {
+		s.files = true
+		return nil
+	}
+}
+
+// envFileSuffix is appended to env keys of fields which read files, whatever the splitter is.
+const envFileSuffix = "_FILE"
+
 type envSource struct {
-	splitter string
-	err      error
-	fields   []structtags.Field
+	splitter     string
+	prefix       string
+	bareFallback bool
+	files        bool
+	err          error
+	fields       []structtags.Field
 }
 
 func Env(options ...EnvOption) Source {
@@ -38,6 +76,10 @@ func Env(options ...EnvOption) Source {
 		}
 	}
 
+	if ret.err == nil && ret.bareFallback && ret.prefix == "" {
+		ret.err = fmt.Errorf("env bare fallback needs a prefix")
+	}
+
 	return &ret
 }
 
@@ -60,19 +102,90 @@ func (s *envSource) Parse(ctx context.Context, args []string) error {
 		return s.err
 	}
 
+	var errs []error
 	for _, field := range s.fields {
-		name := field.Name
+		origin, envValue, exist, err := s.lookup(field)
+		if !exist {
+			continue
+		}
 
-		envKey := strings.ToUpper(strings.Join(name, s.splitter))
-		envValue, exist := os.LookupEnv(envKey)
+		if err == nil {
+			err = field.UnmarshalText([]byte(envValue))
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{
+				Field:  field.Name,
+				Origin: origin,
+				Err:    err,
+			})
+			continue
+		}
+		field.SetOrigin(origin)
+	}
+
+	return errors.Join(errs...)
+}
+
+// lookup returns the value of the first env key of the field which exists.
+// If the field reads files, a "KEY_FILE" key is checked after "KEY", and the value is the content of that file without the trailing newline.
+func (s *envSource) lookup(field structtags.Field) (structtags.Origin, string, bool, error) {
+	for _, key := range s.keys(field) {
+		if value, exist := os.LookupEnv(key); exist {
+			return structtags.Origin{Source: "env", Key: key}, value, true, nil
+		}
+
+		if !s.readFile(field) {
+			continue
+		}
+
+		fileKey := key + envFileSuffix
+		path, exist := os.LookupEnv(fileKey)
 		if !exist {
 			continue
 		}
 
-		if err := field.UnmarshalText([]byte(envValue)); err != nil {
-			return fmt.Errorf("parse env (%s: %q) error: %w", envKey, envValue, err)
+		origin := structtags.Origin{Source: "env", Key: fileKey}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return origin, "", true, err
 		}
+
+		return origin, strings.TrimRight(string(buf), "\r\n"), true, nil
 	}
 
-	return nil
+	return structtags.Origin{}, "", false, nil
+}
+
+// readFile returns true if the field could be read from a file by a "KEY_FILE" key.
+func (s *envSource) readFile(field structtags.Field) bool {
+	return s.files || field.EnvFile
+}
+
+// keys returns env keys of the field, in the order of precedence.
+func (s *envSource) keys(field structtags.Field) []string {
+	if len(field.Env) > 0 {
+		return field.Env
+	}
+
+	bare := strings.ToUpper(strings.Join(field.Name, s.splitter))
+	switch {
+	case s.prefix == "":
+		return []string{bare}
+	case s.bareFallback:
+		return []string{s.prefix + s.splitter + bare, bare}
+	}
+
+	return []string{s.prefix + s.splitter + bare}
+}
+
+func (s *envSource) Keys(field structtags.Field) []structtags.Origin {
+	var ret []structtags.Origin
+	for _, key := range s.keys(field) {
+		ret = append(ret, structtags.Origin{Source: "env", Key: key})
+		if s.readFile(field) {
+			ret = append(ret, structtags.Origin{Source: "env", Key: key + envFileSuffix})
+		}
+	}
+
+	return ret
 }
@@ -3,6 +3,8 @@ package source
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -48,6 +50,31 @@ func TestEnv(t *testing.T) {
 			wantA2: "abc",
 			wantA3: "xyz",
 		},
+		{
+			name:    "WithPrefix",
+			options: []EnvOption{EnvPrefix("app")},
+			envs: map[string]string{
+				"APP_A1":       "123",
+				"L1_A2":        "abc",
+				"APP_L2_L3_A3": "xyz",
+			},
+			wantA1: "123",
+			wantA2: "a2",
+			wantA3: "xyz",
+		},
+		{
+			name:    "WithBareFallback",
+			options: []EnvOption{EnvPrefix("app"), EnvBareFallback()},
+			envs: map[string]string{
+				"APP_A1":   "123",
+				"A1":       "bare",
+				"L1_A2":    "abc",
+				"L2_L3_A3": "xyz",
+			},
+			wantA1: "123",
+			wantA2: "abc",
+			wantA3: "xyz",
+		},
 	}
 
 	for _, tc := range tests {
@@ -80,12 +107,106 @@ func TestEnv(t *testing.T) {
 	}
 }
 
+func TestEnvOverride(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"Primary", map[string]string{"DATABASE_URL": "url"}, "url"},
+		{"Alias", map[string]string{"DB_URL": "alias"}, "alias"},
+		{"PrimaryFirst", map[string]string{"DATABASE_URL": "url", "DB_URL": "alias"}, "url"},
+		{"IgnoreGenerated", map[string]string{"DATABASE_URL_GENERATED": "generated"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var str string
+			fields := []structtags.Field{
+				{Name: []string{"database", "url", "generated"}, Env: []string{"DATABASE_URL", "DB_URL"}, Parser: parserString, Value: reflect.ValueOf(&str).Elem()},
+			}
+
+			src := Env()
+			if err := src.Register(nil, fields); err != nil {
+				t.Fatalf("src.Register(fields) returns error: %v", err)
+			}
+
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			if err := src.Parse(context.Background(), nil); err != nil {
+				t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+			}
+
+			if got, want := str, tc.want; got != want {
+				t.Errorf("after src.Parse(), str = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("from_file\n"), 0o600); err != nil {
+		t.Fatalf("write secret file error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options []EnvOption
+		envFile bool
+		envTag  []string
+		env     map[string]string
+		want    string
+		wantErr bool
+	}{
+		{"Option", []EnvOption{EnvFiles()}, false, nil, map[string]string{"DB_PASSWORD_FILE": secret}, "from_file", false},
+		{"Tag", nil, true, nil, map[string]string{"DB_PASSWORD_FILE": secret}, "from_file", false},
+		{"Disabled", nil, false, nil, map[string]string{"DB_PASSWORD_FILE": secret}, "", false},
+		{"KeyWins", []EnvOption{EnvFiles()}, false, nil, map[string]string{"DB_PASSWORD": "from_env", "DB_PASSWORD_FILE": secret}, "from_env", false},
+		{"WithPrefix", []EnvOption{EnvPrefix("app"), EnvFiles()}, false, nil, map[string]string{"APP_DB_PASSWORD_FILE": secret}, "from_file", false},
+		{"WithSplitter", []EnvOption{EnvSplitter("__"), EnvFiles()}, false, nil, map[string]string{"DB__PASSWORD_FILE": secret}, "from_file", false},
+		{"SplitterNoSuffix", []EnvOption{EnvSplitter("__"), EnvFiles()}, false, nil, map[string]string{"DB__PASSWORD__FILE": secret}, "", false},
+		{"EnvTag", []EnvOption{EnvSplitter("__"), EnvFiles()}, false, []string{"DATABASE_PASSWORD"}, map[string]string{"DATABASE_PASSWORD_FILE": secret}, "from_file", false},
+		{"NoFile", []EnvOption{EnvFiles()}, false, nil, map[string]string{"DB_PASSWORD_FILE": filepath.Join(dir, "not_exist")}, "", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var str string
+			fields := []structtags.Field{
+				{Name: []string{"db", "password"}, Env: tc.envTag, EnvFile: tc.envFile, Parser: parserString, Value: reflect.ValueOf(&str).Elem()},
+			}
+
+			src := Env(tc.options...)
+			if err := src.Register(nil, fields); err != nil {
+				t.Fatalf("src.Register(fields) returns error: %v", err)
+			}
+
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			err := src.Parse(context.Background(), nil)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("src.Parse() = %v, want error: %v", err, tc.wantErr)
+			}
+
+			if got, want := str, tc.want; got != want {
+				t.Errorf("after src.Parse(), str = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
 func TestEnvOptionError(t *testing.T) {
 	tests := []struct {
 		name    string
 		options []EnvOption
 	}{
 		{"EmptySplitter", []EnvOption{EnvSplitter("")}},
+		{"EmptyPrefix", []EnvOption{EnvPrefix("")}},
+		{"BareFallbackWithoutPrefix", []EnvOption{EnvBareFallback()}},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,40 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/googollee/clic/structtags"
+)
+
+// FieldError is an error of a field, like an invalid value from a source or a missing required value.
+type FieldError struct {
+	Field  []string            // Field is the name of the field.
+	Keys   []structtags.Origin // Keys are where the field could be set, like the flag name, the env key or the path in the config file.
+	Origin structtags.Origin   // Origin is where the invalid value comes from. It's empty if the value is missing.
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	b.WriteString(strings.Join(e.Field, "."))
+
+	if len(e.Keys) > 0 {
+		keys := make([]string, 0, len(e.Keys))
+		for _, key := range e.Keys {
+			keys = append(keys, key.String())
+		}
+		fmt.Fprintf(&b, " (%s)", strings.Join(keys, ", "))
+	}
+
+	if e.Origin.Source != "" {
+		fmt.Fprintf(&b, " from %s", e.Origin)
+	}
+
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -2,8 +2,16 @@ package source
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/googollee/clic/structtags"
 )
@@ -15,6 +23,11 @@ type FileCodec interface {
 	Decode(path string, v any) error
 }
 
+// FileWriter is implemented by a [FileCodec] which could write the content to a writer, besides a file.
+type FileWriter interface {
+	Write(w io.Writer, v any) error
+}
+
 type FileOption func(*fileSource) error
 
 func FileFormat(codec FileCodec) FileOption {
@@ -23,10 +36,41 @@ func FileFormat(codec FileCodec) FileOption {
 			return fmt.Errorf("invalid codec: %v", codec)
 		}
 		s.codec = codec
+		s.codecs = nil
 		return nil
 	}
 }
 
+// FileFormats accepts files in formats of all `codecs`, and picks the codec by the extension name of a file, like ".json" or ".yaml".
+// The first codec is used to write configs and templates.
+func FileFormats(codecs ...FileCodec) FileOption {
+	return func(s *fileSource) error {
+		if len(codecs) == 0 || slices.Contains(codecs, nil) {
+			return fmt.Errorf("invalid codecs: %v", codecs)
+		}
+		s.codec = codecs[0]
+		s.codecs = codecs
+		return nil
+	}
+}
+
+// FileExtNamer is implemented by a [FileCodec] which accepts more than one extension name, like "yaml" and "yml".
+type FileExtNamer interface {
+	// ExtNames returns all extension names of the format, without ".".
+	ExtNames() []string
+}
+
+// extNames returns all extension names of the codec.
+func extNames(codec FileCodec) []string {
+	if namer, ok := codec.(FileExtNamer); ok {
+		return namer.ExtNames()
+	}
+
+	return []string{codec.ExtName()}
+}
+
+// FilePathFlag sets the name of the flag of config file paths. The flag could be repeated, or contain paths separated by ",".
+// Files are read in order, and a later file overrides keys in earlier files.
 func FilePathFlag(name string) FileOption {
 	return func(s *fileSource) error {
 		if name == "" {
@@ -37,13 +81,55 @@ func FilePathFlag(name string) FileOption {
 	}
 }
 
+// FileDir loads all files in the directory `dir` with extension names of codecs, like "/etc/myapp/conf.d/*.json", in lexical order.
+// Files in the directory are read before files in the path flag, and a later file overrides keys in earlier files.
+// It's not an error if the directory doesn't exist.
+func FileDir(dir string) FileOption {
+	return func(s *fileSource) error {
+		if dir == "" {
+			return fmt.Errorf("invalid config directory: %q", dir)
+		}
+		s.dir = dir
+		return nil
+	}
+}
+
+// FileDirFlag sets the name of the flag of the config directory. The default value of the flag is the directory set by [FileDir].
+func FileDirFlag(name string) FileOption {
+	return func(s *fileSource) error {
+		if name == "" {
+			return fmt.Errorf("invalid flag name of the config directory: %q", name)
+		}
+		s.dirFlag = name
+		return nil
+	}
+}
+
+// FileSearchPaths sets candidate paths of the config file, like "./app.json", "$XDG_CONFIG_HOME/app/config.json" and "/etc/app/config.json".
+// If the path flag is not set, the first existing file in `paths` is loaded. Env vars in paths are expanded, and a path with an unset env var is skipped.
+func FileSearchPaths(paths ...string) FileOption {
+	return func(s *fileSource) error {
+		if slices.Contains(paths, "") {
+			return fmt.Errorf("invalid search paths: %q", paths)
+		}
+		s.searchPaths = paths
+		return nil
+	}
+}
+
 type fileSource struct {
 	codec        FileCodec
+	codecs       []FileCodec // codecs are formats picked by extension names, or nil to use `codec` for all files.
 	filepathFlag string
-	filepath     string
+	filepaths    filePaths
+	dir          string
+	dirFlag      string
+	searchPaths  []string
+	stats        []fileStat
 	err          error
 
-	value reflect.Value
+	fields []structtags.Field
+	value  reflect.Value
 }
 
 func File(options ...FileOption) Source {
@@ -70,20 +156,289 @@ func (s *fileSource) Register(fset FlagSet, fields []structtags.Field) error {
 		return s.err
 	}
 
-	s.value = newFromFields(fields, 0, s.codec.TagName()+":\"%s\"")
-	fset.StringVar(&s.filepath, s.filepathFlag, "", "the path of the config file")
+	s.fields = fields
+	s.value = newFromFields(fields, 0, s.tagFmt())
+	s.filepaths = filePaths{}
+	fset.Var(&s.filepaths, s.filepathFlag, "the paths of config files, separated by \",\"")
+	if s.dirFlag != "" {
+		fset.StringVar(&s.dir, s.dirFlag, s.dir, "the directory of config files")
+	}
 
 	return nil
 }
 
+func (s *fileSource) Flags() []string {
+	ret := []string{s.filepathFlag}
+	if s.dirFlag != "" {
+		ret = append(ret, s.dirFlag)
+	}
+
+	return ret
+}
+
 func (s *fileSource) Parse(ctx context.Context, args []string) error {
 	if s.err != nil {
 		return s.err
 	}
 
-	if s.filepath == "" {
-		return nil
+	// The flag set could be parsed again after this, and the next parsing should replace paths.
+	s.filepaths.isSet = false
+
+	paths, err := s.paths()
+	if err != nil {
+		return err
+	}
+	s.stats = statFiles(paths)
+
+	var errs []error
+	for _, path := range paths {
+		slog.DebugContext(ctx, "load config file", "path", path)
+		codec, err := s.codecOf(path)
+		if err != nil {
+			return err
+		}
+
+		err = codec.Decode(path, s.value.Interface())
+		fieldErrs := takeFieldErrors(s.value, path)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, redactTOMLError(err, s.fields))
+		}
+		errs = append(errs, fieldErrs...)
+
+		for _, field := range s.fields {
+			if origin := field.Origin(); origin.Source == "file" && origin.File == "" {
+				origin.File = path
+				field.SetOrigin(origin)
+			}
+		}
 	}
 
-	return s.codec.Decode(s.filepath, s.value.Interface())
+	return errors.Join(errs...)
+}
+
+// takeFieldErrors returns errors of fields in `v` created by [newFromFields] when decoding the file `path`, and clears them.
+func takeFieldErrors(v reflect.Value, path string) []error {
+	v = reflect.Indirect(v)
+
+	var ret []error
+	for i := range v.NumField() {
+		field, ok := v.Field(i).Interface().(fileField)
+		if !ok {
+			ret = append(ret, takeFieldErrors(v.Field(i), path)...)
+			continue
+		}
+
+		if err := *field.err; err != nil {
+			err.Origin.File = path
+			ret = append(ret, err)
+			*field.err = nil
+		}
+	}
+
+	return ret
+}
+
+// paths returns paths of all config files to load, in order.
+func (s *fileSource) paths() ([]string, error) {
+	paths, err := s.dirPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.filepaths.given {
+		paths = append(paths, s.filepaths.paths...)
+	} else if path, ok := s.searchPath(); ok {
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// fileStat is the state of a config file, to check whether the file changes.
+type fileStat struct {
+	path    string
+	exist   bool
+	size    int64
+	modTime int64
+}
+
+func statFiles(paths []string) []fileStat {
+	ret := make([]fileStat, 0, len(paths))
+	for _, path := range paths {
+		stat := fileStat{path: path}
+		if info, err := os.Stat(path); err == nil {
+			stat.exist = true
+			stat.size = info.Size()
+			stat.modTime = info.ModTime().UnixNano()
+		}
+		ret = append(ret, stat)
+	}
+
+	return ret
+}
+
+// Changed returns true if config files change after the last parsing, including adding or removing a file.
+// It returns false if it can't list files, like the config directory is not readable.
+func (s *fileSource) Changed() bool {
+	if s.err != nil {
+		return false
+	}
+
+	paths, err := s.paths()
+	if err != nil {
+		return false
+	}
+
+	return !slices.Equal(statFiles(paths), s.stats)
+}
+
+// tagFmt returns the format of struct tags for all codecs, like `json:"%[1]s" yaml:"%[1]s"`.
+func (s *fileSource) tagFmt() string {
+	if len(s.codecs) == 0 {
+		return s.codec.TagName() + ":\"%[1]s\""
+	}
+
+	tags := make([]string, 0, len(s.codecs))
+	for _, codec := range s.codecs {
+		tags = append(tags, codec.TagName()+":\"%[1]s\"")
+	}
+
+	return strings.Join(tags, " ")
+}
+
+// codecOf returns the codec to decode the file with `path`.
+func (s *fileSource) codecOf(path string) (FileCodec, error) {
+	if len(s.codecs) == 0 {
+		return s.codec, nil
+	}
+
+	if codec := s.codecByExt(path); codec != nil {
+		return codec, nil
+	}
+
+	var supported []string
+	for _, codec := range s.codecs {
+		for _, name := range extNames(codec) {
+			supported = append(supported, "."+name)
+		}
+	}
+
+	return nil, fmt.Errorf("config file %s: unknown format %q, supported formats: %s", path, filepath.Ext(path), strings.Join(supported, ", "))
+}
+
+// codecByExt returns the codec with the extension name of `path`, or nil if no codec matches.
+func (s *fileSource) codecByExt(path string) FileCodec {
+	codecs := s.codecs
+	if len(codecs) == 0 {
+		codecs = []FileCodec{s.codec}
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, codec := range codecs {
+		if slices.Contains(extNames(codec), ext) {
+			return codec
+		}
+	}
+
+	return nil
+}
+
+// dirPaths returns paths of files in the config directory with extension names of codecs, in lexical order.
+func (s *fileSource) dirPaths() ([]string, error) {
+	if s.dir == "" {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("config directory %s: %w", s.dir, err)
+	}
+
+	var ret []string
+	for _, entry := range entries {
+		if entry.IsDir() || s.codecByExt(entry.Name()) == nil {
+			continue
+		}
+		ret = append(ret, filepath.Join(s.dir, entry.Name()))
+	}
+
+	return ret, nil
+}
+
+// searchPath returns the first existing file in search paths.
+func (s *fileSource) searchPath() (string, bool) {
+	for _, path := range s.searchPaths {
+		unset := false
+		path = os.Expand(path, func(key string) string {
+			value, ok := os.LookupEnv(key)
+			unset = unset || !ok || value == ""
+			return value
+		})
+		if unset {
+			continue
+		}
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *fileSource) Keys(field structtags.Field) []structtags.Origin {
+	return []structtags.Origin{fileKey(field)}
+}
+
+// WriteConfig writes current values of all fields to `w` in the format of the codec. Secrets are redacted.
+// It should be called after calling Parse().
+func (s *fileSource) WriteConfig(w io.Writer) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if !s.value.IsValid() {
+		return fmt.Errorf("file source is not registered")
+	}
+
+	writer, ok := s.codec.(FileWriter)
+	if !ok {
+		return fmt.Errorf("codec %T can't write to a writer", s.codec)
+	}
+
+	return writer.Write(w, s.value.Interface())
+}
+
+// filePaths is the [flag.Value] of config file paths. The first setting in a parsing replaces existing paths, and others append.
+type filePaths struct {
+	paths []string
+	isSet bool // isSet is true if the flag is set in the current parsing.
+	given bool // given is true if the flag is ever set, even with an empty value.
+}
+
+func (p *filePaths) String() string {
+	if p == nil {
+		return ""
+	}
+
+	return strings.Join(p.paths, ",")
+}
+
+func (p *filePaths) Set(str string) error {
+	if !p.isSet {
+		p.paths = nil
+	}
+	p.isSet = true
+	p.given = true
+
+	for path := range strings.SplitSeq(str, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			p.paths = append(p.paths, path)
+		}
+	}
+
+	return nil
 }
@@ -2,6 +2,7 @@ package source
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 )
 
@@ -22,7 +23,14 @@ func (JSON) Encode(path string, v any) error {
 	}
 	defer f.Close()
 
-	return json.NewEncoder(f).Encode(v)
+	return JSON{}.Write(f, v)
+}
+
+// Write writes `v` to `w` with indentation, so people could read or diff the output.
+func (JSON) Write(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
 }
 
 func (JSON) Decode(path string, v any) error {
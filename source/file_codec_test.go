@@ -1,12 +1,18 @@
 package source
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic/structtags"
 )
 
 func TestCodec(t *testing.T) {
@@ -16,7 +22,9 @@ func TestCodec(t *testing.T) {
 		wantTag string
 		wantExt string
 	}{
-		{JSON{}, "{\"int\":1,\"str\":\"str\"}\n", "json", "json"},
+		{JSON{}, "{\n  \"int\": 1,\n  \"str\": \"str\"\n}\n", "json", "json"},
+		{YAML{}, "int: 1\nmap:\n  str: str\n", "yaml", "yaml"},
+		{TOML{}, "int = 1\n\n[map]\n  str = \"str\"\n", "toml", "toml"},
 	}
 
 	for _, tc := range tests {
@@ -70,3 +78,277 @@ func TestCodec(t *testing.T) {
 		})
 	}
 }
+
+func TestCodecTableLayout(t *testing.T) {
+	tests := []struct {
+		codec FileCodec
+		path  string
+	}{
+		{TOML{}, "./testdata/valid.toml"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			a1, a2, a3 = "a1", "a2", "a3"
+			value := newFromFields(fields, 0, tc.codec.TagName()+":\"%s\"")
+
+			if err := tc.codec.Decode(tc.path, value.Interface()); err != nil {
+				t.Fatalf("tc.codec.Decode(%q) returns error: %v", tc.path, err)
+			}
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := tc.codec.Encode(fname, value.Interface()); err != nil {
+				t.Fatalf("tc.codec.Encode() returns error: %v", err)
+			}
+
+			want, err := os.ReadFile(tc.path)
+			if err != nil {
+				t.Fatalf("os.ReadFile(%q) error: %v", tc.path, err)
+			}
+
+			got, err := os.ReadFile(fname)
+			if err != nil {
+				t.Fatalf("os.ReadFile(%q) error: %v", fname, err)
+			}
+
+			if diff := cmp.Diff(string(got), string(want)); diff != "" {
+				t.Errorf("the diff content after decoding and encoding: (-got, +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONScalar(t *testing.T) {
+	type Config struct {
+		Int   int           `clic:"int"`
+		Uint  uint          `clic:"uint"`
+		Float float64       `clic:"float"`
+		Bool  bool          `clic:"bool"`
+		Dur   time.Duration `clic:"dur"`
+		Str   string        `clic:"str,default"`
+	}
+
+	tests := []struct {
+		content string
+		want    Config
+		wantOk  bool
+	}{
+		{`{"int":-1,"uint":2,"float":1.5,"bool":true,"dur":1000000000}`, Config{Int: -1, Uint: 2, Float: 1.5, Bool: true, Dur: time.Second, Str: "default"}, true},
+		{`{"int":"-1","uint":"2","float":"1.5","bool":"true","dur":"1s","str":"str"}`, Config{Int: -1, Uint: 2, Float: 1.5, Bool: true, Dur: time.Second, Str: "str"}, true},
+		{`{"int":null,"str":null}`, Config{Str: "default"}, true},
+		{`{"int":1.5}`, Config{}, false},
+		{`{"uint":-1}`, Config{}, false},
+		{`{"dur":1.5}`, Config{}, false},
+		{`{"bool":1.5}`, Config{}, false},
+		{`{"str":[]}`, Config{}, false},
+		{`{"str":{}}`, Config{}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.content, func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+			if err != nil {
+				t.Fatalf("structtags.ParseStruct() returns error: %v", err)
+			}
+			value := newFromFields(fields, 0, `json:"%s"`)
+
+			err = json.Unmarshal([]byte(tc.content), value.Interface())
+			if err == nil {
+				err = errors.Join(takeFieldErrors(value, "")...)
+			}
+			if gotOk := err == nil; gotOk != tc.wantOk {
+				t.Fatalf("json.Unmarshal(%s) = %v, want ok: %v", tc.content, err, tc.wantOk)
+			}
+			if !tc.wantOk {
+				return
+			}
+
+			if diff := cmp.Diff(cfg, tc.want); diff != "" {
+				t.Errorf("json.Unmarshal(%s) diff: (-got, +want)\n%s", tc.content, diff)
+			}
+		})
+	}
+}
+
+func TestYAMLInt(t *testing.T) {
+	type Config struct {
+		Hex   int           `clic:"hex"`
+		Octal uint          `clic:"octal"`
+		Zero  int           `clic:"zero"`
+		UZero uint          `clic:"uzero"`
+		Big   uint64        `clic:"big"`
+		Dur   time.Duration `clic:"dur"`
+		Ints  []int         `clic:"ints"`
+	}
+	content := "hex: 0x10\noctal: 0o17\nzero: 0755\nuzero: 0755\nbig: 18446744073709551615\ndur: 1_000\nints: [0x1, -0b10]\n"
+	want := Config{Hex: 16, Octal: 15, Zero: 493, UZero: 493, Big: 18446744073709551615, Dur: 1000, Ints: []int{1, -2}}
+
+	var cfg Config
+	fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+	if err != nil {
+		t.Fatalf("structtags.ParseStruct() returns error: %v", err)
+	}
+	value := newFromFields(fields, 0, `yaml:"%s"`)
+
+	fname := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(fname, []byte(content), 0o644); err != nil {
+		t.Fatalf("can't write temp file %q: %v", fname, err)
+	}
+
+	err = YAML{}.Decode(fname, value.Interface())
+	if err == nil {
+		err = errors.Join(takeFieldErrors(value, fname)...)
+	}
+	if err != nil {
+		t.Fatalf("YAML{}.Decode(%q) returns error: %v", content, err)
+	}
+
+	if diff := cmp.Diff(cfg, want); diff != "" {
+		t.Errorf("YAML{}.Decode(%q) diff: (-got, +want)\n%s", content, diff)
+	}
+}
+
+func TestCodecSlice(t *testing.T) {
+	type Config struct {
+		Strs []string        `clic:"strs"`
+		Durs []time.Duration `clic:"durs"`
+	}
+	want := Config{
+		Strs: []string{"a", "b"},
+		Durs: []time.Duration{time.Second, time.Minute},
+	}
+
+	tests := []struct {
+		codec   FileCodec
+		content string
+	}{
+		{JSON{}, `{"strs":["a","b"],"durs":[1000000000,"1m"]}`},
+		{JSON{}, `{"strs":"a,b","durs":"1s,1m"}`},
+		{YAML{}, "strs: [a, b]\ndurs:\n  - 1000000000\n  - 1m\n"},
+		{TOML{}, "strs = [\"a\", \"b\"]\ndurs = [1000000000, \"1m\"]\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+			if err != nil {
+				t.Fatalf("structtags.ParseStruct() returns error: %v", err)
+			}
+			value := newFromFields(fields, 0, tc.codec.TagName()+":\"%s\"")
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := os.WriteFile(fname, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("can't write temp file %q: %v", fname, err)
+			}
+
+			if err := tc.codec.Decode(fname, value.Interface()); err != nil {
+				t.Fatalf("tc.codec.Decode(%q) returns error: %v", tc.content, err)
+			}
+
+			if diff := cmp.Diff(cfg, want); diff != "" {
+				t.Errorf("tc.codec.Decode(%q) diff: (-got, +want)\n%s", tc.content, diff)
+			}
+		})
+	}
+}
+
+func TestCodecMap(t *testing.T) {
+	type Config struct {
+		Labels map[string]string `clic:"labels"`
+		Limits map[string]int    `clic:"limits"`
+	}
+	want := Config{
+		Labels: map[string]string{"a": "1", "b": "x"},
+		Limits: map[string]int{"a": 1, "b": 2},
+	}
+
+	tests := []struct {
+		codec   FileCodec
+		content string
+	}{
+		{JSON{}, `{"labels":{"a":1,"b":"x"},"limits":{"a":1,"b":"2"}}`},
+		{JSON{}, `{"labels":"a=1,b=x","limits":"a=1,b=2"}`},
+		{YAML{}, "labels:\n  a: 1\n  b: x\nlimits: {a: 1, b: 2}\n"},
+		{TOML{}, "[labels]\na = 1\nb = \"x\"\n\n[limits]\na = 1\nb = 2\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+			if err != nil {
+				t.Fatalf("structtags.ParseStruct() returns error: %v", err)
+			}
+			value := newFromFields(fields, 0, tc.codec.TagName()+":\"%s\"")
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := os.WriteFile(fname, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("can't write temp file %q: %v", fname, err)
+			}
+
+			if err := tc.codec.Decode(fname, value.Interface()); err != nil {
+				t.Fatalf("tc.codec.Decode(%q) returns error: %v", tc.content, err)
+			}
+
+			if diff := cmp.Diff(cfg, want); diff != "" {
+				t.Errorf("tc.codec.Decode(%q) diff: (-got, +want)\n%s", tc.content, diff)
+			}
+		})
+	}
+}
+
+func TestCodecSecretError(t *testing.T) {
+	type Config struct {
+		Token   string            `clic:"token" secret:"true"`
+		Timeout time.Duration     `clic:"timeout" secret:"true"`
+		Limits  map[string]int    `clic:"limits" secret:"true"`
+		Tokens  []string          `clic:"tokens" secret:"true"`
+		Labels  map[string]string `clic:"labels" secret:"true"`
+	}
+
+	tests := []struct {
+		codec   FileCodec
+		content string
+		secret  string
+	}{
+		{JSON{}, `{"token":{"s3cr3t":1}}`, "s3cr3t"},
+		{JSON{}, `{"tokens":[["s3cr3t"]]}`, "s3cr3t"},
+		{JSON{}, `{"labels":{"a":{"s3cr3t":1}}}`, "s3cr3t"},
+		{JSON{}, `{"timeout":99999999999999999999}`, "99999999999999999999"},
+		{JSON{}, `{"limits":{"s3cr3t":"x"}}`, "s3cr3t"},
+		{YAML{}, "timeout: 99999999999999999999\n", "99999999999999999999"},
+		{YAML{}, "limits:\n  s3cr3t: x\n", "s3cr3t"},
+		{TOML{}, "[limits]\ns3cr3t = \"x\"\n", "s3cr3t"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+			if err != nil {
+				t.Fatalf("structtags.ParseStruct() returns error: %v", err)
+			}
+			value := newFromFields(fields, 0, tc.codec.TagName()+":\"%s\"")
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := os.WriteFile(fname, []byte(tc.content), 0o644); err != nil {
+				t.Fatalf("can't write temp file %q: %v", fname, err)
+			}
+
+			err = tc.codec.Decode(fname, value.Interface())
+			if err == nil {
+				err = errors.Join(takeFieldErrors(value, fname)...)
+			}
+			if err == nil {
+				t.Fatalf("tc.codec.Decode(%q) returns no error", tc.content)
+			}
+			for ; err != nil; err = errors.Unwrap(err) {
+				if strings.Contains(err.Error(), tc.secret) {
+					t.Errorf("tc.codec.Decode(%q) error %q contains the secret value", tc.content, err)
+				}
+			}
+		})
+	}
+}
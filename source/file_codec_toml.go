@@ -0,0 +1,130 @@
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/googollee/clic/structtags"
+)
+
+type TOML struct{}
+
+func (TOML) TagName() string {
+	return "toml"
+}
+
+func (TOML) ExtName() string {
+	return "toml"
+}
+
+func (TOML) Encode(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return TOML{}.Write(f, v)
+}
+
+func (TOML) Write(w io.Writer, v any) error {
+	return toml.NewEncoder(w).Encode(v)
+}
+
+func (TOML) Decode(path string, v any) error {
+	_, err := toml.DecodeFile(path, v)
+	return err
+}
+
+func (TOML) WriteTemplate(w io.Writer, fields []structtags.Field) error {
+	type table struct {
+		path   []string
+		fields []structtags.Field
+	}
+
+	// Keys of a table must be written before sub-tables, so group fields by tables in the order of first appearance.
+	var tables []*table
+	for _, field := range fields {
+		path := field.Name[:len(field.Name)-1]
+		i := slices.IndexFunc(tables, func(t *table) bool { return slices.Equal(t.path, path) })
+		if i < 0 {
+			i = len(tables)
+			if len(path) == 0 {
+				// Root-level keys must be written before any table header, or they belong to the table.
+				i = 0
+			}
+			tables = slices.Insert(tables, i, &table{path: path})
+		}
+		tables[i].fields = append(tables[i].fields, field)
+	}
+
+	var b strings.Builder
+	for i, table := range tables {
+		indent := ""
+		if len(table.path) > 0 {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			indent = strings.Repeat("  ", len(table.path)-1)
+
+			keys := make([]string, 0, len(table.path))
+			for _, key := range table.path {
+				keys = append(keys, tomlKey(key))
+			}
+			fmt.Fprintf(&b, "%s[%s]\n", indent, strings.Join(keys, "."))
+			indent += "  "
+		}
+
+		for _, field := range table.fields {
+			for _, line := range commentLines(field.Description) {
+				fmt.Fprintf(&b, "%s# %s\n", indent, line)
+			}
+
+			value, err := tomlValue(fileField{Field: field}.dumpValue())
+			if err != nil {
+				return fmt.Errorf("field %v: %w", field.Name, err)
+			}
+			fmt.Fprintf(&b, "%s%s = %s\n", indent, tomlKey(field.Name[len(field.Name)-1]), value)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// redactTOMLError returns an error without the value if `err` is a syntax error at a secret field in `fields`,
+// because the message of a syntax error may contain the value, like `pass = hunter2`.
+func redactTOMLError(err error, fields []structtags.Field) error {
+	var parseErr toml.ParseError
+	if !errors.As(err, &parseErr) || parseErr.LastKey == "" {
+		return err
+	}
+
+	for _, field := range fields {
+		key := fileKey(field).Key
+		if field.Secret && (parseErr.LastKey == key || strings.HasPrefix(parseErr.LastKey, key+".")) {
+			return fmt.Errorf("toml: line %d: can't parse the secret value of field %v", parseErr.Position.Line, field.Name)
+		}
+	}
+
+	return err
+}
+
+// tomlKey returns `key` as a bare key if possible, or a quoted key.
+func tomlKey(key string) string {
+	bare := key != "" && !strings.ContainsFunc(key, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-')
+	})
+	if bare {
+		return key
+	}
+
+	buf, _ := json.Marshal(key)
+	return string(buf)
+}
@@ -0,0 +1,92 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/googollee/clic/structtags"
+	"gopkg.in/yaml.v3"
+)
+
+type YAML struct{}
+
+func (YAML) TagName() string {
+	return "yaml"
+}
+
+func (YAML) ExtName() string {
+	return "yaml"
+}
+
+func (YAML) ExtNames() []string {
+	return []string{"yaml", "yml"}
+}
+
+func (YAML) Encode(path string, v any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return YAML{}.Write(f, v)
+}
+
+func (YAML) Write(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+func (YAML) Decode(path string, v any) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(buf, v)
+}
+
+func (YAML) WriteTemplate(w io.Writer, fields []structtags.Field) error {
+	node, err := yamlTemplate(fields, 0)
+	if err != nil {
+		return err
+	}
+
+	return YAML{}.Write(w, node)
+}
+
+// yamlTemplate returns a mapping node of an name-ordered array of `fields`, with descriptions as comments of keys.
+func yamlTemplate(fields []structtags.Field, index int) (*yaml.Node, error) {
+	ret := &yaml.Node{Kind: yaml.MappingNode}
+
+	for len(fields) > 0 {
+		end := findEndFieldHaveSameIndex(fields, index)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fields[0].Name[index]}
+
+		var value *yaml.Node
+		if end == 1 && index == len(fields[0].Name)-1 {
+			key.HeadComment = strings.Join(commentLines(fields[0].Description), "\n")
+			value = &yaml.Node{}
+			if err := value.Encode(fileField{Field: fields[0]}.dumpValue()); err != nil {
+				return nil, fmt.Errorf("field %v: %w", fields[0].Name, err)
+			}
+		} else {
+			var err error
+			if value, err = yamlTemplate(fields[:end], index+1); err != nil {
+				return nil, err
+			}
+		}
+
+		ret.Content = append(ret.Content, key, value)
+		fields = fields[end:]
+	}
+
+	return ret, nil
+}
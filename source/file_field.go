@@ -0,0 +1,445 @@
+package source
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/googollee/clic/structtags"
+	"gopkg.in/yaml.v3"
+)
+
+// fileField is the leaf type of the struct created by [newFromFields].
+// It adapts a field to codecs which need more than [encoding.TextUnmarshaler].
+type fileField struct {
+	structtags.Field
+
+	// err is the error of decoding an invalid value, which is returned by [fileSource.Parse] instead, so codecs go on decoding other fields.
+	err **FieldError
+}
+
+// fail records `err` of decoding the field from `origin`. It returns `err` if the field can't record it.
+func (f fileField) fail(origin structtags.Origin, err error) error {
+	if f.err == nil {
+		return fmt.Errorf("field %v: %w", f.Name, err)
+	}
+
+	*f.err = &FieldError{Field: f.Name, Origin: origin, Err: err}
+	return nil
+}
+
+// fileKey returns the origin of a field set by a config file, whose key is the path of the field in the file.
+func fileKey(field structtags.Field) structtags.Origin {
+	return structtags.Origin{
+		Source: "file",
+		Key:    strings.Join(field.Name, "."),
+	}
+}
+
+// keyText returns the quoted key of a map field in errors, or [structtags.SecretMask] if the field is a secret.
+func (f fileField) keyText(key string) string {
+	if f.Secret {
+		return structtags.SecretMask
+	}
+
+	return strconv.Quote(key)
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// elemType returns the type of one value in the field, which is the element type for a slice or map field.
+func (f fileField) elemType() reflect.Type {
+	if f.ElemParser != nil {
+		return f.Value.Type().Elem()
+	}
+
+	return f.Value.Type()
+}
+
+// numberText converts the text of a number to the text which the parser of type `t` accepts.
+// A number for a [time.Duration] means nanoseconds, the same as [encoding/json].
+func numberText(num string, t reflect.Type) ([]byte, error) {
+	if t != durationType {
+		return []byte(num), nil
+	}
+
+	ns, err := strconv.ParseInt(num, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse %s to nanoseconds: %w", num, err)
+	}
+
+	return []byte(time.Duration(ns).String()), nil
+}
+
+// UnmarshalJSON accepts JSON strings, numbers, booleans and null, an array of them for a slice field, or an object of them for a map field.
+func (f fileField) UnmarshalJSON(buf []byte) error {
+	if buf[0] == 'n' {
+		return nil
+	}
+
+	origin := fileKey(f.Field)
+	if err := f.unmarshalJSON(buf); err != nil {
+		return f.fail(origin, err)
+	}
+	f.SetOrigin(origin)
+
+	return nil
+}
+
+func (f fileField) unmarshalJSON(buf []byte) error {
+	if buf[0] == '{' && f.KeyParser != nil {
+		var elems map[string]json.RawMessage
+		if err := json.Unmarshal(buf, &elems); err != nil {
+			return err
+		}
+
+		f.Clear()
+		for key, elem := range elems {
+			text, err := jsonText(elem, f.elemType())
+			if err == nil && text == nil {
+				err = fmt.Errorf("want a value, got null")
+			}
+			if err == nil {
+				err = f.SetIndexText([]byte(key), text)
+			}
+			if err != nil {
+				return fmt.Errorf("key %s: %w", f.keyText(key), f.RedactError(err))
+			}
+		}
+
+		return nil
+	}
+
+	if buf[0] == '[' && f.ElemParser != nil && f.KeyParser == nil {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(buf, &elems); err != nil {
+			return err
+		}
+
+		f.Clear()
+		for i, elem := range elems {
+			text, err := jsonText(elem, f.elemType())
+			if err == nil && text == nil {
+				err = fmt.Errorf("want a value, got null")
+			}
+			if err == nil {
+				err = f.AppendText(text)
+			}
+			if err != nil {
+				return fmt.Errorf("index %d: %w", i, f.RedactError(err))
+			}
+		}
+
+		return nil
+	}
+
+	text, err := jsonText(buf, f.Value.Type())
+	if err == nil {
+		err = f.UnmarshalText(text)
+	}
+
+	return f.RedactError(err)
+}
+
+// jsonText returns the text of a JSON scalar, or nil for null.
+func jsonText(buf []byte, t reflect.Type) ([]byte, error) {
+	switch buf[0] {
+	case 'n':
+		return nil, nil
+	case '"':
+		var str string
+		if err := json.Unmarshal(buf, &str); err != nil {
+			return nil, err
+		}
+		return []byte(str), nil
+	case 't', 'f':
+		return buf, nil
+	case '{', '[':
+		return nil, fmt.Errorf("want a string, number, boolean or null, got %s", buf)
+	}
+
+	return numberText(string(buf), t)
+}
+
+func (f fileField) UnmarshalYAML(node *yaml.Node) error {
+	origin := fileKey(f.Field)
+	origin.Line = node.Line
+	if err := f.unmarshalYAML(node); err != nil {
+		return f.fail(origin, err)
+	}
+	f.SetOrigin(origin)
+
+	return nil
+}
+
+func (f fileField) unmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode && f.KeyParser != nil {
+		f.Clear()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("want a scalar key")
+			}
+
+			setIndex := func(text []byte) error {
+				return f.SetIndexText([]byte(key.Value), text)
+			}
+			if err := f.unmarshalYAMLScalar(value, setIndex); err != nil {
+				return fmt.Errorf("key %s: %w", f.keyText(key.Value), err)
+			}
+		}
+
+		return nil
+	}
+
+	if node.Kind == yaml.SequenceNode && f.ElemParser != nil && f.KeyParser == nil {
+		f.Clear()
+		for i, elem := range node.Content {
+			if err := f.unmarshalYAMLScalar(elem, f.AppendText); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+		}
+
+		return nil
+	}
+
+	return f.unmarshalYAMLScalar(node, f.UnmarshalText)
+}
+
+func (f fileField) unmarshalYAMLScalar(node *yaml.Node, unmarshal func([]byte) error) error {
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("want a scalar value")
+	}
+
+	text := []byte(node.Value)
+	if node.ShortTag() == "!!int" {
+		// YAML integers could be like 0x10, 0o17 or 1_000, so parsers get the decimal text.
+		num := node.Value
+		var i int64
+		var u uint64
+		if err := node.Decode(&i); err == nil {
+			num = strconv.FormatInt(i, 10)
+		} else if err := node.Decode(&u); err == nil {
+			num = strconv.FormatUint(u, 10)
+		}
+
+		var err error
+		if text, err = numberText(num, f.elemType()); err != nil {
+			return f.RedactError(err)
+		}
+	}
+
+	return unmarshal(text)
+}
+
+var _ toml.Unmarshaler = fileField{}
+
+func (f fileField) UnmarshalTOML(data any) error {
+	origin := fileKey(f.Field)
+	if err := f.unmarshalTOML(data); err != nil {
+		return f.fail(origin, err)
+	}
+	f.SetOrigin(origin)
+
+	return nil
+}
+
+func (f fileField) unmarshalTOML(data any) error {
+	if elems, ok := data.(map[string]any); ok && f.KeyParser != nil {
+		f.Clear()
+		for key, elem := range elems {
+			text, err := tomlText(elem, f.elemType())
+			if err == nil {
+				err = f.SetIndexText([]byte(key), text)
+			}
+			if err != nil {
+				return fmt.Errorf("key %s: %w", f.keyText(key), f.RedactError(err))
+			}
+		}
+
+		return nil
+	}
+
+	if elems, ok := data.([]any); ok && f.ElemParser != nil && f.KeyParser == nil {
+		f.Clear()
+		for i, elem := range elems {
+			text, err := tomlText(elem, f.elemType())
+			if err == nil {
+				err = f.AppendText(text)
+			}
+			if err != nil {
+				return fmt.Errorf("index %d: %w", i, f.RedactError(err))
+			}
+		}
+
+		return nil
+	}
+
+	text, err := tomlText(data, f.Value.Type())
+	if err == nil {
+		err = f.UnmarshalText(text)
+	}
+
+	return f.RedactError(err)
+}
+
+// tomlText returns the text of a TOML primitive value.
+func tomlText(data any, t reflect.Type) ([]byte, error) {
+	switch v := data.(type) {
+	case string:
+		return []byte(v), nil
+	case bool:
+		return strconv.AppendBool(nil, v), nil
+	case int64:
+		return numberText(strconv.FormatInt(v, 10), t)
+	case float64:
+		return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+	case encoding.TextMarshaler:
+		return v.MarshalText()
+	}
+
+	return nil, fmt.Errorf("want a primitive value, got %T", data)
+}
+
+var (
+	stringerType      = reflect.TypeFor[fmt.Stringer]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// dumpValue returns the value of the field in basic types which codecs could write:
+// bool, int64, uint64, float32, float64 or string for a scalar, []any for a slice and map[string]any for a map.
+// A secret value is replaced with [structtags.SecretMask].
+func (f fileField) dumpValue() any {
+	if buf, _ := f.RedactedText(); f.Secret && len(buf) > 0 {
+		return string(buf)
+	}
+
+	if f.KeyParser != nil {
+		ret := make(map[string]any, f.Value.Len())
+		iter := f.Value.MapRange()
+		for iter.Next() {
+			ret[fmt.Sprint(iter.Key().Interface())] = dumpScalar(iter.Value())
+		}
+		return ret
+	}
+
+	if f.ElemParser != nil {
+		ret := make([]any, 0, f.Value.Len())
+		for i := range f.Value.Len() {
+			ret = append(ret, dumpScalar(f.Value.Index(i)))
+		}
+		return ret
+	}
+
+	return dumpScalar(f.Value)
+}
+
+// dumpScalar returns a bool or a number as it is, or the text of other values, like "1m0s" for a [time.Duration].
+func dumpScalar(v reflect.Value) any {
+	if v.Type().Implements(stringerType) || v.Type().Implements(textMarshalerType) {
+		return fmt.Sprint(v.Interface())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32:
+		// A float32 keeps its type, so codecs format it in 32 bits, like 0.1 instead of 0.10000000149011612.
+		return float32(v.Float())
+	case reflect.Float64:
+		return v.Float()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func (f fileField) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.dumpValue())
+}
+
+func (f fileField) MarshalYAML() (any, error) {
+	return f.dumpValue(), nil
+}
+
+var _ toml.Marshaler = fileField{}
+
+func (f fileField) MarshalTOML() ([]byte, error) {
+	return tomlValue(f.dumpValue())
+}
+
+// tomlValue returns the TOML text of a value returned by [fileField.dumpValue]. A map is written as an inline table.
+func tomlValue(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case bool:
+		return strconv.AppendBool(nil, v), nil
+	case int64:
+		return strconv.AppendInt(nil, v, 10), nil
+	case uint64:
+		return strconv.AppendUint(nil, v, 10), nil
+	case float32:
+		return tomlFloat(float64(v), 32), nil
+	case float64:
+		return tomlFloat(v, 64), nil
+	case string:
+		// A JSON string is also a valid TOML basic string.
+		return json.Marshal(v)
+	case []any:
+		ret := []byte{'['}
+		for i, elem := range v {
+			if i > 0 {
+				ret = append(ret, ", "...)
+			}
+			buf, err := tomlValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, buf...)
+		}
+		return append(ret, ']'), nil
+	case map[string]any:
+		ret := []byte{'{'}
+		for i, key := range slices.Sorted(maps.Keys(v)) {
+			if i > 0 {
+				ret = append(ret, ", "...)
+			}
+			keyBuf, err := json.Marshal(key)
+			if err != nil {
+				return nil, err
+			}
+			buf, err := tomlValue(v[key])
+			if err != nil {
+				return nil, err
+			}
+			ret = append(append(append(ret, keyBuf...), " = "...), buf...)
+		}
+		return append(ret, '}'), nil
+	}
+
+	return nil, fmt.Errorf("can't write %T to TOML", v)
+}
+
+// tomlFloat returns the TOML text of a float with `bitSize` bits.
+func tomlFloat(v float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(v):
+		return []byte("nan")
+	case math.IsInf(v, 1):
+		return []byte("inf")
+	case math.IsInf(v, -1):
+		return []byte("-inf")
+	}
+
+	return strconv.AppendFloat(nil, v, 'g', -1, bitSize)
+}
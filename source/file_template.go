@@ -0,0 +1,72 @@
+package source
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/googollee/clic/structtags"
+)
+
+// FileTemplateWriter is implemented by a [FileCodec] which could write a config template with descriptions of fields as comments.
+type FileTemplateWriter interface {
+	// WriteTemplate writes values of `fields` to `w`, with descriptions as comments.
+	WriteTemplate(w io.Writer, fields []structtags.Field) error
+}
+
+// WriteTemplate writes a config template of `fields` to `w` in the format of the codec.
+// Each field is set to its default value, except that a secret field is left empty, and each description is a comment if the format allows it.
+func (s *fileSource) WriteTemplate(w io.Writer, fields []structtags.Field) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	fields, err := defaultFields(fields)
+	if err != nil {
+		return err
+	}
+
+	if writer, ok := s.codec.(FileTemplateWriter); ok {
+		return writer.WriteTemplate(w, fields)
+	}
+
+	writer, ok := s.codec.(FileWriter)
+	if !ok {
+		return fmt.Errorf("codec %T can't write to a writer", s.codec)
+	}
+
+	return writer.Write(w, newFromFields(fields, 0, s.tagFmt()).Interface())
+}
+
+// defaultFields returns copies of `fields` with new values set to default values.
+// A secret field is left empty with a comment, because a template used as it is shouldn't set the secret to the mask or the default value.
+func defaultFields(fields []structtags.Field) ([]structtags.Field, error) {
+	ret := make([]structtags.Field, 0, len(fields))
+	for _, field := range fields {
+		field.Value = reflect.New(field.Value.Type()).Elem()
+		if field.Secret {
+			field.Secret = false
+			field.Description = strings.Join(append(commentLines(field.Description), "secret, fill in the value"), "\n")
+			ret = append(ret, field)
+			continue
+		}
+		if field.DefaultString != "" {
+			if err := field.UnmarshalText([]byte(field.DefaultString)); err != nil {
+				return nil, fmt.Errorf("field %v: %w", field.Name, err)
+			}
+		}
+		ret = append(ret, field)
+	}
+
+	return ret, nil
+}
+
+// commentLines returns lines of a comment with the description, or nil if the description is empty.
+func commentLines(description string) []string {
+	if description == "" {
+		return nil
+	}
+
+	return strings.Split(description, "\n")
+}
@@ -2,10 +2,18 @@ package source
 
 import (
 	"bytes"
+	"errors"
 	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic/structtags"
 )
 
 func TestFile(t *testing.T) {
@@ -19,7 +27,7 @@ func TestFile(t *testing.T) {
 		{
 			name:     "FromValue",
 			options:  []FileOption{},
-			wantHelp: "  -config string\n    \tthe path of the config file\n",
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
 			args:     []string{"-config", "./testdata/valid.json"},
 			wantA1:   "123",
 			wantA2:   "abc",
@@ -28,7 +36,7 @@ func TestFile(t *testing.T) {
 		{
 			name:     "FromDefault",
 			options:  []FileOption{},
-			wantHelp: "  -config string\n    \tthe path of the config file\n",
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
 			args:     []string{"-config", "./testdata/empty.json"},
 			wantA1:   "a1",
 			wantA2:   "a2",
@@ -37,12 +45,57 @@ func TestFile(t *testing.T) {
 		{
 			name:     "WithFlagFormat",
 			options:  []FileOption{FileFormat(JSON{}), FilePathFlag("c")},
-			wantHelp: "  -c string\n    \tthe path of the config file\n",
+			wantHelp: "  -c value\n    \tthe paths of config files, separated by \",\"\n",
 			args:     []string{"-c", "./testdata/valid.json"},
 			wantA1:   "123",
 			wantA2:   "abc",
 			wantA3:   "xyz",
 		},
+		{
+			name:     "FromTypedValue",
+			options:  []FileOption{},
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
+			args:     []string{"-config", "./testdata/typed.json"},
+			wantA1:   "123",
+			wantA2:   "true",
+			wantA3:   "a3",
+		},
+		{
+			name:     "WithYAML",
+			options:  []FileOption{FileFormat(YAML{})},
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
+			args:     []string{"-config", "./testdata/valid.yaml"},
+			wantA1:   "123",
+			wantA2:   "abc",
+			wantA3:   "xyz",
+		},
+		{
+			name:     "WithTOML",
+			options:  []FileOption{FileFormat(TOML{})},
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
+			args:     []string{"-config", "./testdata/valid.toml"},
+			wantA1:   "123",
+			wantA2:   "abc",
+			wantA3:   "xyz",
+		},
+		{
+			name:     "Layered",
+			options:  []FileOption{},
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
+			args:     []string{"-config", "./testdata/valid.json", "-config", "./testdata/overlay.json"},
+			wantA1:   "123",
+			wantA2:   "overlay",
+			wantA3:   "xyz",
+		},
+		{
+			name:     "LayeredInOneValue",
+			options:  []FileOption{},
+			wantHelp: "  -config value\n    \tthe paths of config files, separated by \",\"\n",
+			args:     []string{"-config", "./testdata/overlay.json,./testdata/typed.json"},
+			wantA1:   "123",
+			wantA2:   "true",
+			wantA3:   "a3",
+		},
 	}
 
 	for _, tc := range tests {
@@ -105,6 +158,34 @@ func TestFile(t *testing.T) {
 		}
 	})
 
+	t.Run("InvalidYAMLValue", func(t *testing.T) {
+		fset := flag.NewFlagSet("", flag.ContinueOnError)
+		src := File(FileFormat(YAML{}))
+
+		if err := src.Register(fset, fields); err != nil {
+			t.Fatalf("src.Prepare(fields) returns error: %v", err)
+		}
+
+		args := []string{"-config", "testdata/valid.yaml,testdata/invalid_value.yaml"}
+		if err := fset.Parse(args); err != nil {
+			t.Fatalf("fset.Parse() error: %v", err)
+		}
+
+		err := src.Parse(t.Context(), args)
+		if err == nil {
+			t.Fatalf("src.Parse() = nil, want an error")
+		}
+
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("src.Parse() = %v, want a %T", err, fieldErr)
+		}
+		wantOrigin := structtags.Origin{Source: "file", Key: "l1.a2", File: "testdata/invalid_value.yaml", Line: 3}
+		if diff := cmp.Diff(fieldErr.Origin, wantOrigin); diff != "" {
+			t.Errorf("origin of src.Parse() error diff: (-got, +want)\n%s", diff)
+		}
+	})
+
 	t.Run("EmptyConfigFile", func(t *testing.T) {
 		fset := flag.NewFlagSet("", flag.ContinueOnError)
 		src := File()
@@ -123,6 +204,30 @@ func TestFile(t *testing.T) {
 		}
 	})
 
+	t.Run("ParseAgain", func(t *testing.T) {
+		fset := flag.NewFlagSet("", flag.ContinueOnError)
+		src := File()
+		a1, a2, a3 = "a1", "a2", "a3"
+
+		if err := src.Register(fset, fields); err != nil {
+			t.Fatalf("src.Prepare(fields) returns error: %v", err)
+		}
+
+		args := []string{"-config", "testdata/valid.json", "-config", "testdata/overlay.json"}
+		for range 2 {
+			if err := fset.Parse(args); err != nil {
+				t.Fatalf("fset.Parse() error: %v", err)
+			}
+			if err := src.Parse(t.Context(), args); err != nil {
+				t.Fatalf("src.Parse() = %v, want no error", err)
+			}
+		}
+
+		if got, want := fset.Lookup("config").Value.String(), "testdata/valid.json,testdata/overlay.json"; got != want {
+			t.Errorf("config paths = %q, want: %q", got, want)
+		}
+	})
+
 	t.Run("InvalidFlag", func(t *testing.T) {
 		fset := flag.NewFlagSet("", flag.ContinueOnError)
 		src := File()
@@ -142,6 +247,211 @@ func TestFile(t *testing.T) {
 	})
 }
 
+func TestFileDir(t *testing.T) {
+	tests := []struct {
+		name           string
+		options        []FileOption
+		args           []string
+		wantA1, wantA2 string
+	}{
+		{"Dir", []FileOption{FileDir("testdata/conf.d")}, nil, "override", "base"},
+		{"DirThenFiles", []FileOption{FileDir("testdata/conf.d")}, []string{"-config", "testdata/overlay.json"}, "override", "overlay"},
+		{"MissingDir", []FileOption{FileDir("testdata/not_exist.d")}, nil, "a1", "a2"},
+		{"DirFlag", []FileOption{FileDir("testdata/not_exist.d"), FileDirFlag("config-dir")}, []string{"-config-dir", "testdata/conf.d"}, "override", "base"},
+		{"DefaultDirFlag", []FileOption{FileDir("testdata/conf.d"), FileDirFlag("config-dir")}, nil, "override", "base"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			src := File(tc.options...)
+			a1, a2, a3 = "a1", "a2", "a3"
+
+			if err := src.Register(fset, fields); err != nil {
+				t.Fatalf("src.Prepare(fields) returns error: %v", err)
+			}
+
+			if err := fset.Parse(tc.args); err != nil {
+				t.Fatalf("fset.Parse() error: %v", err)
+			}
+
+			if err := src.Parse(t.Context(), tc.args); err != nil {
+				t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+			}
+
+			if got, want := a1, tc.wantA1; got != want {
+				t.Errorf("after src.Parse(), a1 = %q, want: %q", got, want)
+			}
+			if got, want := a2, tc.wantA2; got != want {
+				t.Errorf("after src.Parse(), a2 = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFileSearchPaths(t *testing.T) {
+	t.Setenv("CLIC_TEST_DIR", "testdata")
+	t.Setenv("CLIC_TEST_EMPTY", "")
+
+	tests := []struct {
+		name           string
+		paths          []string
+		args           []string
+		wantA1, wantA2 string
+	}{
+		{"FirstExisting", []string{"testdata/not_exist.json", "testdata/valid.json", "testdata/overlay.json"}, nil, "123", "abc"},
+		{"ExpandEnv", []string{"$CLIC_TEST_DIR/overlay.json"}, nil, "a1", "overlay"},
+		{"SkipUnsetEnv", []string{"$CLIC_TEST_EMPTY/testdata/valid.json", "${CLIC_TEST_UNSET}testdata/valid.json", "testdata/overlay.json"}, nil, "a1", "overlay"},
+		{"SkipDir", []string{"testdata", "testdata/overlay.json"}, nil, "a1", "overlay"},
+		{"NotFound", []string{"testdata/not_exist.json"}, nil, "a1", "a2"},
+		{"FlagFirst", []string{"testdata/valid.json"}, []string{"-config", "testdata/overlay.json"}, "a1", "overlay"},
+		{"EmptyFlag", []string{"testdata/valid.json"}, []string{"-config", ""}, "a1", "a2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			src := File(FileSearchPaths(tc.paths...))
+			a1, a2, a3 = "a1", "a2", "a3"
+
+			if err := src.Register(fset, fields); err != nil {
+				t.Fatalf("src.Prepare(fields) returns error: %v", err)
+			}
+
+			if err := fset.Parse(tc.args); err != nil {
+				t.Fatalf("fset.Parse() error: %v", err)
+			}
+
+			if err := src.Parse(t.Context(), tc.args); err != nil {
+				t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+			}
+
+			if got, want := a1, tc.wantA1; got != want {
+				t.Errorf("after src.Parse(), a1 = %q, want: %q", got, want)
+			}
+			if got, want := a2, tc.wantA2; got != want {
+				t.Errorf("after src.Parse(), a2 = %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFileChanged(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"a1":"123"}`), 0o600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	src := File(FileDir(dir))
+	a1, a2, a3 = "a1", "a2", "a3"
+
+	if err := src.Register(fset, fields); err != nil {
+		t.Fatalf("src.Register(fields) returns error: %v", err)
+	}
+	if err := src.Parse(t.Context(), nil); err != nil {
+		t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+	}
+
+	watcher, ok := src.(Watcher)
+	if !ok {
+		t.Fatalf("file source %T doesn't implement Watcher", src)
+	}
+	if watcher.Changed() {
+		t.Errorf("after src.Parse(), src.Changed() = true, want: false")
+	}
+
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("change times of file error: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Errorf("after modifying the file, src.Changed() = false, want: true")
+	}
+
+	if err := src.Parse(t.Context(), nil); err != nil {
+		t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+	}
+	if watcher.Changed() {
+		t.Errorf("after src.Parse() again, src.Changed() = true, want: false")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "local.json"), []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	if !watcher.Changed() {
+		t.Errorf("after adding a file, src.Changed() = false, want: true")
+	}
+}
+
+func TestFileFormats(t *testing.T) {
+	tests := []struct {
+		name                   string
+		options                []FileOption
+		args                   []string
+		wantA1, wantA2, wantA3 string
+	}{
+		{"ByExt", nil, []string{"-config", "testdata/valid.toml,testdata/overlay.yml"}, "123", "yml", "xyz"},
+		{"Layered", nil, []string{"-config", "testdata/valid.yaml,testdata/overlay.json"}, "123", "overlay", "xyz"},
+		{"Dir", []FileOption{FileDir("testdata/conf.d")}, nil, "ignored", "base", "a3"},
+		{"SearchPaths", []FileOption{FileSearchPaths("testdata/not_exist.json", "testdata/overlay.yml")}, nil, "a1", "yml", "a3"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			src := File(append([]FileOption{FileFormats(JSON{}, YAML{}, TOML{})}, tc.options...)...)
+			a1, a2, a3 = "a1", "a2", "a3"
+
+			if err := src.Register(fset, fields); err != nil {
+				t.Fatalf("src.Prepare(fields) returns error: %v", err)
+			}
+
+			if err := fset.Parse(tc.args); err != nil {
+				t.Fatalf("fset.Parse() error: %v", err)
+			}
+
+			if err := src.Parse(t.Context(), tc.args); err != nil {
+				t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+			}
+
+			if got, want := a1, tc.wantA1; got != want {
+				t.Errorf("after src.Parse(), a1 = %q, want: %q", got, want)
+			}
+			if got, want := a2, tc.wantA2; got != want {
+				t.Errorf("after src.Parse(), a2 = %q, want: %q", got, want)
+			}
+			if got, want := a3, tc.wantA3; got != want {
+				t.Errorf("after src.Parse(), a3 = %q, want: %q", got, want)
+			}
+		})
+	}
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		fset := flag.NewFlagSet("", flag.ContinueOnError)
+		src := File(FileFormats(JSON{}, YAML{}))
+
+		if err := src.Register(fset, fields); err != nil {
+			t.Fatalf("src.Prepare(fields) returns error: %v", err)
+		}
+
+		args := []string{"-config", "testdata/valid.toml"}
+		if err := fset.Parse(args); err != nil {
+			t.Fatalf("fset.Parse() error: %v", err)
+		}
+
+		err := src.Parse(t.Context(), args)
+		if err == nil {
+			t.Fatalf("src.Parse() = nil, want an error")
+		}
+
+		if got, want := err.Error(), `config file testdata/valid.toml: unknown format ".toml", supported formats: .json, .yaml, .yml`; got != want {
+			t.Errorf("src.Parse() = %q, want: %q", got, want)
+		}
+	})
+}
+
 func TestFileError(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -149,6 +459,11 @@ func TestFileError(t *testing.T) {
 	}{
 		{"EmptyCodec", []FileOption{FileFormat(nil)}},
 		{"EmptyPathFlag", []FileOption{FilePathFlag("")}},
+		{"EmptyDir", []FileOption{FileDir("")}},
+		{"EmptyDirFlag", []FileOption{FileDirFlag("")}},
+		{"EmptySearchPath", []FileOption{FileSearchPaths("testdata/valid.json", "")}},
+		{"EmptyFormats", []FileOption{FileFormats()}},
+		{"NilFormat", []FileOption{FileFormats(JSON{}, nil)}},
 	}
 
 	for _, tc := range tests {
@@ -177,3 +492,243 @@ func TestFileError(t *testing.T) {
 		})
 	}
 }
+
+func TestFileWriteConfig(t *testing.T) {
+	type Config struct {
+		Str    string         `clic:"str,a\"b"`
+		Int    int            `clic:"int,-1"`
+		Float  float64        `clic:"float,0.5"`
+		Single float32        `clic:"single,0.1"`
+		Bool   bool           `clic:"bool,true"`
+		Dur    time.Duration  `clic:"dur,1m"`
+		Ints   []int          `clic:"ints" default:"1,2"`
+		Map    map[string]int `clic:"map" default:"b=2,a=1"`
+		Secret string         `clic:"secret,pass" secret:"true"`
+	}
+
+	tests := []struct {
+		codec FileCodec
+		want  string
+	}{
+		{JSON{}, `{
+  "c": {
+    "str": "a\"b",
+    "int": -1,
+    "float": 0.5,
+    "single": 0.1,
+    "bool": true,
+    "dur": "1m0s",
+    "ints": [
+      1,
+      2
+    ],
+    "map": {
+      "a": 1,
+      "b": 2
+    },
+    "secret": "******"
+  }
+}
+`},
+		{YAML{}, "c:\n  str: a\"b\n  int: -1\n  float: 0.5\n  single: 0.1\n  bool: true\n  dur: 1m0s\n  ints:\n    - 1\n    - 2\n  map:\n    a: 1\n    b: 2\n  secret: '******'\n"},
+		{TOML{}, "[c]\n  str = \"a\\\"b\"\n  int = -1\n  float = 0.5\n  single = 0.1\n  bool = true\n  dur = \"1m0s\"\n  ints = [1, 2]\n  map = {\"a\" = 1, \"b\" = 2}\n  secret = \"******\"\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{"c"})
+			if err != nil {
+				t.Fatalf("ParseStruct() error: %v", err)
+			}
+
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			src := File(FileFormat(tc.codec))
+			if err := src.Register(fset, fields); err != nil {
+				t.Fatalf("src.Register(fields) returns error: %v", err)
+			}
+
+			var buf strings.Builder
+			if err := src.(ConfigWriter).WriteConfig(&buf); err != nil {
+				t.Fatalf("src.WriteConfig() returns error: %v", err)
+			}
+
+			if diff := cmp.Diff(buf.String(), tc.want); diff != "" {
+				t.Errorf("src.WriteConfig() diff: (-got, +want)\n%s", diff)
+			}
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := os.WriteFile(fname, []byte(buf.String()), 0o600); err != nil {
+				t.Fatalf("write file error: %v", err)
+			}
+			if err := tc.codec.Decode(fname, src.(*fileSource).value.Interface()); err != nil {
+				t.Fatalf("decode the written config error: %v", err)
+			}
+			if cfg.Dur != time.Minute || len(cfg.Ints) != 2 || cfg.Map["b"] != 2 || cfg.Str != "a\"b" {
+				t.Errorf("decode the written config, got: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestFileWriteTemplate(t *testing.T) {
+	type Config struct {
+		Host  string `clic:"host,localhost,the host\nof the server"`
+		Inner struct {
+			Port int `clic:"port,80,the port"`
+		} `clic:"inner"`
+		Labels map[string]string `clic:"labels" default:"a=1"`
+		Token  string            `clic:"token,abc" secret:"true"`
+	}
+
+	tests := []struct {
+		codec FileCodec
+		want  string
+	}{
+		{JSON{}, `{
+  "c": {
+    "host": "localhost",
+    "inner": {
+      "port": 80
+    },
+    "labels": {
+      "a": "1"
+    },
+    "token": ""
+  }
+}
+`},
+		{YAML{}, "c:\n  # the host\n  # of the server\n  host: localhost\n  inner:\n    # the port\n    port: 80\n  labels:\n    a: \"1\"\n  # secret, fill in the value\n  token: \"\"\n"},
+		{TOML{}, "[c]\n  # the host\n  # of the server\n  host = \"localhost\"\n  labels = {\"a\" = \"1\"}\n  # secret, fill in the value\n  token = \"\"\n\n  [c.inner]\n    # the port\n    port = 80\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%T", tc.codec), func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{"c"})
+			if err != nil {
+				t.Fatalf("ParseStruct() error: %v", err)
+			}
+
+			// the template uses default values, instead of current values.
+			cfg.Host = "example.com"
+			cfg.Inner.Port = 8080
+
+			src := File(FileFormat(tc.codec))
+			var buf strings.Builder
+			if err := src.(TemplateWriter).WriteTemplate(&buf, fields); err != nil {
+				t.Fatalf("src.WriteTemplate() returns error: %v", err)
+			}
+
+			if diff := cmp.Diff(buf.String(), tc.want); diff != "" {
+				t.Errorf("src.WriteTemplate() diff: (-got, +want)\n%s", diff)
+			}
+
+			fname := filepath.Join(t.TempDir(), "config."+tc.codec.ExtName())
+			if err := os.WriteFile(fname, []byte(buf.String()), 0o600); err != nil {
+				t.Fatalf("write file error: %v", err)
+			}
+			if err := tc.codec.Decode(fname, newFromFields(fields, 0, tc.codec.TagName()+":\"%s\"").Interface()); err != nil {
+				t.Fatalf("decode the template error: %v", err)
+			}
+			if cfg.Host != "localhost" || cfg.Inner.Port != 80 || cfg.Token != "" {
+				t.Errorf("decode the template, got: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestFileWriteTemplateRootKeys(t *testing.T) {
+	type Config struct {
+		Inner struct {
+			Port int `clic:"port,80"`
+		} `clic:"inner"`
+		Host  string `clic:"host,localhost"`
+		Other struct {
+			Name string `clic:"name,other"`
+		} `clic:"other"`
+		Debug bool `clic:"debug,true"`
+	}
+
+	var cfg Config
+	fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{})
+	if err != nil {
+		t.Fatalf("ParseStruct() error: %v", err)
+	}
+
+	src := File(FileFormat(TOML{}))
+	var buf strings.Builder
+	if err := src.(TemplateWriter).WriteTemplate(&buf, fields); err != nil {
+		t.Fatalf("src.WriteTemplate() returns error: %v", err)
+	}
+
+	want := "host = \"localhost\"\ndebug = true\n\n[inner]\n  port = 80\n\n[other]\n  name = \"other\"\n"
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("src.WriteTemplate() diff: (-got, +want)\n%s", diff)
+	}
+
+	fname := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(fname, []byte(buf.String()), 0o600); err != nil {
+		t.Fatalf("write file error: %v", err)
+	}
+	cfg = Config{}
+	if err := (TOML{}).Decode(fname, newFromFields(fields, 0, `toml:"%s"`).Interface()); err != nil {
+		t.Fatalf("decode the template error: %v", err)
+	}
+	if cfg.Host != "localhost" || !cfg.Debug || cfg.Inner.Port != 80 || cfg.Other.Name != "other" {
+		t.Errorf("decode the template, got: %+v", cfg)
+	}
+}
+
+func TestFileSecretSyntaxError(t *testing.T) {
+	type Config struct {
+		Name   string            `clic:"name"`
+		Pass   string            `clic:"pass" secret:"true"`
+		Tokens map[string]string `clic:"tokens" secret:"true"`
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		wantSecret bool
+	}{
+		{"Secret", "[c]\npass = hunter2\n", true},
+		{"SecretMap", "[c.tokens]\nadmin = hunter2\n", true},
+		{"NotSecret", "[c]\nname = hunter2\n", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var cfg Config
+			fields, err := structtags.ParseStruct(reflect.ValueOf(&cfg), []string{"c"})
+			if err != nil {
+				t.Fatalf("ParseStruct() error: %v", err)
+			}
+
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatalf("write file error: %v", err)
+			}
+
+			fset := flag.NewFlagSet("", flag.ContinueOnError)
+			src := File(FileFormat(TOML{}))
+			if err := src.Register(fset, fields); err != nil {
+				t.Fatalf("src.Register(fields) returns error: %v", err)
+			}
+			args := []string{"-config", path}
+			if err := fset.Parse(args); err != nil {
+				t.Fatalf("fset.Parse() error: %v", err)
+			}
+
+			err = src.Parse(t.Context(), args)
+			if err == nil {
+				t.Fatalf("src.Parse() returns no error")
+			}
+			for ; err != nil; err = errors.Unwrap(err) {
+				if got := strings.Contains(err.Error(), "hunter"); got == tc.wantSecret {
+					t.Errorf("src.Parse() error %q contains the value: %v, want: %v", err, got, !tc.wantSecret)
+				}
+			}
+		})
+	}
+}
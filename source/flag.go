@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/googollee/clic/structtags"
@@ -26,8 +27,9 @@ func FlagSplitter(splitter string) FlagOption {
 type flagSource struct {
 	splitter string
 
-	fset FlagSet
-	err  error
+	fset   FlagSet
+	values []*flagValue
+	err    error
 }
 
 func Flag(opt ...FlagOption) Source {
@@ -54,12 +56,31 @@ func (s *flagSource) Register(fset FlagSet, fields []structtags.Field) error {
 	}
 
 	s.fset = fset
+	s.values = nil
 
 	for _, field := range fields {
-		names := field.Name
+		usage := field.Description
+		if field.Deprecated != "" {
+			usage = strings.TrimSpace(fmt.Sprintf("%s (deprecated: %s)", usage, field.Deprecated))
+		}
 
-		key := strings.ToLower(strings.Join(names, s.splitter))
-		fset.TextVar(&field, key, field, field.Description)
+		isSet := new(bool)
+		keys := s.Keys(field)
+		name := strings.TrimPrefix(keys[0].Key, "-")
+		for i, origin := range keys {
+			value := &flagValue{field: field, origin: origin, isSet: isSet}
+			alias := strings.TrimPrefix(origin.Key, "-")
+
+			switch {
+			case i == 0:
+				fset.Var(value, alias, usage)
+			case alias == field.Short:
+				fset.Var(value, alias, "short for -"+name)
+			default:
+				fset.Var(value, alias, "alias of -"+name)
+			}
+			s.values = append(s.values, value)
+		}
 	}
 
 	return nil
@@ -70,9 +91,84 @@ func (s *flagSource) Parse(ctx context.Context, args []string) error {
 		return s.err
 	}
 
+	for _, value := range s.values {
+		*value.isSet = false
+		value.err = nil
+	}
+
 	if err := s.fset.Parse(args); err != nil {
 		return err
 	}
 
+	var errs []error
+	for _, value := range s.values {
+		if value.err != nil {
+			errs = append(errs, &FieldError{
+				Field:  value.field.Name,
+				Origin: value.origin,
+				Err:    value.err,
+			})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *flagSource) Keys(field structtags.Field) []structtags.Origin {
+	names := field.Flag
+	if len(names) == 0 {
+		names = []string{strings.ToLower(strings.Join(field.Name, s.splitter))}
+	}
+	if field.Short != "" {
+		names = append(slices.Clip(names), field.Short)
+	}
+
+	ret := make([]structtags.Origin, 0, len(names))
+	for _, name := range names {
+		ret = append(ret, FlagKey(name))
+	}
+
+	return ret
+}
+
+// FlagKey returns the origin of a value set by the flag with `name`.
+func FlagKey(name string) structtags.Origin {
+	return structtags.Origin{Source: "flag", Key: "-" + name}
+}
+
+// flagValue is the [flag.Value] of a flag name of a field. A slice field appends values when the flag repeats.
+// All names of a field share `isSet`.
+type flagValue struct {
+	field  structtags.Field
+	origin structtags.Origin
+	isSet  *bool
+	err    error // err is the error of setting a secret field, which is returned by [flagSource.Parse] instead.
+}
+
+func (v *flagValue) String() string {
+	if v == nil || !v.field.Value.IsValid() {
+		return ""
+	}
+
+	buf, _ := v.field.RedactedText()
+	return string(buf)
+}
+
+func (v *flagValue) Set(str string) error {
+	if v.field.ElemParser != nil && !*v.isSet {
+		v.field.Clear()
+	}
+	*v.isSet = true
+
+	if err := v.field.AppendText([]byte(str)); err != nil {
+		if v.field.Secret {
+			// [flag.FlagSet] puts the value in the error, so keep the error and report it later.
+			v.err = err
+			return nil
+		}
+		return err
+	}
+	v.field.SetOrigin(v.origin)
+
 	return nil
 }
@@ -3,9 +3,12 @@ package source
 import (
 	"bytes"
 	"flag"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/googollee/clic/structtags"
 )
 
 func TestFlag(t *testing.T) {
@@ -139,3 +142,78 @@ func TestFlagError(t *testing.T) {
 		})
 	}
 }
+
+func TestFlagSlice(t *testing.T) {
+	var strs []string
+	field := structtags.Field{
+		Name:       []string{"strs"},
+		Separator:  ",",
+		Parser:     func(v reflect.Value, str string) error { return fmt.Errorf("should not be called") },
+		ElemParser: parserString,
+		Value:      reflect.ValueOf(&strs).Elem(),
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	src := Flag()
+
+	if err := src.Register(fset, []structtags.Field{field}); err != nil {
+		t.Fatalf("src.Register(fields) returns error: %v", err)
+	}
+
+	args := []string{"-strs", "a,b", "-strs", "c"}
+	if err := fset.Parse(args); err != nil {
+		t.Fatalf("fset.Parse() error: %v", err)
+	}
+
+	strs = []string{"from", "env"}
+	if err := src.Parse(t.Context(), args); err != nil {
+		t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+	}
+
+	if diff := cmp.Diff(strs, []string{"a,b", "c"}); diff != "" {
+		t.Errorf("after src.Parse(), strs diff: (-got, +want)\n%s", diff)
+	}
+}
+
+func TestFlagAlias(t *testing.T) {
+	var strs []string
+	field := structtags.Field{
+		Name:       []string{"database", "hosts"},
+		Flag:       []string{"hosts", "host"},
+		Short:      "H",
+		Separator:  ",",
+		Parser:     func(v reflect.Value, str string) error { return fmt.Errorf("should not be called") },
+		ElemParser: parserString,
+		Value:      reflect.ValueOf(&strs).Elem(),
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	src := Flag()
+
+	if err := src.Register(fset, []structtags.Field{field}); err != nil {
+		t.Fatalf("src.Register(fields) returns error: %v", err)
+	}
+
+	if f := fset.Lookup("database.hosts"); f != nil {
+		t.Errorf("fset.Lookup(%q) = %v, want: nil", "database.hosts", f)
+	}
+	if got, want := fset.Lookup("host").Usage, "alias of -hosts"; got != want {
+		t.Errorf("usage of -host = %q, want: %q", got, want)
+	}
+	if got, want := fset.Lookup("H").Usage, "short for -hosts"; got != want {
+		t.Errorf("usage of -H = %q, want: %q", got, want)
+	}
+
+	args := []string{"-host", "a", "-hosts", "b", "-H", "c"}
+	if err := fset.Parse(args); err != nil {
+		t.Fatalf("fset.Parse() error: %v", err)
+	}
+
+	if err := src.Parse(t.Context(), args); err != nil {
+		t.Fatalf("src.Parse() should return no error, which is not: %v", err)
+	}
+
+	if diff := cmp.Diff(strs, []string{"a", "b", "c"}); diff != "" {
+		t.Errorf("after src.Parse(), strs diff: (-got, +want)\n%s", diff)
+	}
+}
@@ -1,12 +1,16 @@
 package source
 
-import "encoding"
+import (
+	"encoding"
+	"flag"
+)
 
 type FlagSet interface {
 	PrintDefaults()
 	Parse([]string) error
 	Parsed() bool
 
+	Var(value flag.Value, name string, usage string)
 	TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler, usage string)
 	StringVar(v *string, name string, defaultValue string, usage string)
 	BoolVar(v *bool, name string, defaultValue bool, usage string)
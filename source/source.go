@@ -2,6 +2,7 @@ package source
 
 import (
 	"context"
+	"io"
 
 	"github.com/googollee/clic/structtags"
 )
@@ -11,3 +12,34 @@ type Source interface {
 	Parse(ctx context.Context, args []string) error
 	Error() error
 }
+
+// Keyer is implemented by a [Source] which could tell where a field is read from.
+type Keyer interface {
+	// Keys returns origins of the field when the source sets the field, like flag names or env keys.
+	// The first one is the primary key, and others are aliases.
+	Keys(field structtags.Field) []structtags.Origin
+}
+
+// FlagOwner is implemented by a [Source] which registers flags for itself, like the flag of config file paths.
+type FlagOwner interface {
+	// Flags returns names of flags which the source registers for itself. Fields can't use these names as flags.
+	Flags() []string
+}
+
+// ConfigWriter is implemented by a [Source] which could write current values of fields in its format, like a config file source.
+type ConfigWriter interface {
+	// WriteConfig writes current values of all fields to `w`, with secrets redacted.
+	WriteConfig(w io.Writer) error
+}
+
+// TemplateWriter is implemented by a [Source] which could write a config template, like a config file source.
+type TemplateWriter interface {
+	// WriteTemplate writes `fields` with default values to `w`, with descriptions as comments if the format allows it.
+	WriteTemplate(w io.Writer, fields []structtags.Field) error
+}
+
+// Watcher is implemented by a [Source] whose content could change after parsing, like config files.
+type Watcher interface {
+	// Changed returns true if the content changes after the last parsing.
+	Changed() bool
+}
@@ -0,0 +1,225 @@
+package structtags
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraint is a limit of the value of a field, declared by a tag like `min:"1"`.
+//
+// For a slice or map field, "min", "max", "enum" and "pattern" check each element, and "minlen" and "maxlen" check the number of elements.
+// Constraints check the value from any source, including the default value, and aren't checked if the field has neither.
+type Constraint struct {
+	Name  string // Name is the name of the tag, like "min".
+	Value string // Value is the value of the tag, like "1".
+
+	check func(v reflect.Value) error
+}
+
+// Check returns an error if `v` doesn't meet the constraint.
+func (c Constraint) Check(v reflect.Value) error {
+	return c.check(v)
+}
+
+type newConstraintFunc func(f Field, elemType reflect.Type, value string) (func(v reflect.Value) error, error)
+
+// constraintTags are supported constraint tags, in the order of checking.
+var constraintTags = []struct {
+	name string
+	new  newConstraintFunc
+}{
+	{"min", newConstraintRange(1)},
+	{"max", newConstraintRange(-1)},
+	{"enum", newConstraintEnum},
+	{"pattern", newConstraintPattern},
+	{"minlen", newConstraintLen(1)},
+	{"maxlen", newConstraintLen(-1)},
+}
+
+func getConstraints(sfield reflect.StructField, f Field) ([]Constraint, error) {
+	elemType := f.Value.Type()
+	if f.ElemParser != nil {
+		elemType = elemType.Elem()
+	}
+
+	var ret []Constraint
+	for _, tag := range constraintTags {
+		value, ok := sfield.Tag.Lookup(tag.name)
+		if !ok {
+			continue
+		}
+
+		check, err := tag.new(f, elemType, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s tag %q for field %v: %w", tag.name, value, f.Name, err)
+		}
+
+		ret = append(ret, Constraint{
+			Name:  tag.name,
+			Value: value,
+			check: check,
+		})
+	}
+
+	return ret, nil
+}
+
+// Validate checks the value of the field with all constraints.
+func (f Field) Validate() error {
+	if f.Secret {
+		return f.validateSecret()
+	}
+
+	for _, c := range f.Constraints {
+		if err := c.Check(f.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// elemParser returns the parser of one value, which is the element parser for a slice or map field.
+func (f Field) elemParser() ParseFieldFunc {
+	if f.ElemParser != nil {
+		return f.ElemParser
+	}
+
+	return f.Parser
+}
+
+// checkElems calls `check` with `v`, or each element if `v` is a slice or map.
+func checkElems(isContainer bool, check func(v reflect.Value) error) func(v reflect.Value) error {
+	if !isContainer {
+		return check
+	}
+
+	return func(v reflect.Value) error {
+		if v.Kind() == reflect.Map {
+			iter := v.MapRange()
+			for iter.Next() {
+				if err := check(iter.Value()); err != nil {
+					return fmt.Errorf("value of key %v: %w", iter.Key().Interface(), err)
+				}
+			}
+			return nil
+		}
+
+		for i := range v.Len() {
+			if err := check(v.Index(i)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+}
+
+func newConstraintRange(sign int) newConstraintFunc {
+	return func(f Field, elemType reflect.Type, value string) (func(v reflect.Value) error, error) {
+		if _, ok := compareValues(reflect.Zero(elemType), reflect.Zero(elemType)); !ok {
+			return nil, fmt.Errorf("%s is not a number", elemType)
+		}
+
+		limit := reflect.New(elemType).Elem()
+		if err := f.elemParser()(limit, value); err != nil {
+			return nil, err
+		}
+
+		op := ">="
+		if sign < 0 {
+			op = "<="
+		}
+
+		return checkElems(f.ElemParser != nil, func(v reflect.Value) error {
+			if ret, _ := compareValues(v, limit); ret*sign < 0 {
+				return fmt.Errorf("%v must be %s %v", v.Interface(), op, limit.Interface())
+			}
+			return nil
+		}), nil
+	}
+}
+
+// compareValues compares 2 numbers with the same type, or returns false if they are not numbers.
+func compareValues(a, b reflect.Value) (int, bool) {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float()), true
+	}
+
+	return 0, false
+}
+
+func newConstraintEnum(f Field, elemType reflect.Type, value string) (func(v reflect.Value) error, error) {
+	names := strings.Split(value, ",")
+	allowed := make([]any, 0, len(names))
+	for _, name := range names {
+		v := reflect.New(elemType).Elem()
+		if err := f.elemParser()(v, name); err != nil {
+			return nil, err
+		}
+		allowed = append(allowed, v.Interface())
+	}
+
+	return checkElems(f.ElemParser != nil, func(v reflect.Value) error {
+		if !slices.ContainsFunc(allowed, func(a any) bool { return reflect.DeepEqual(a, v.Interface()) }) {
+			return fmt.Errorf("%v must be one of [%s]", v.Interface(), value)
+		}
+		return nil
+	}), nil
+}
+
+func newConstraintPattern(f Field, elemType reflect.Type, value string) (func(v reflect.Value) error, error) {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkElems(f.ElemParser != nil, func(v reflect.Value) error {
+		if str := fmt.Sprint(v.Interface()); !re.MatchString(str) {
+			return fmt.Errorf("%q must match pattern %q", str, value)
+		}
+		return nil
+	}), nil
+}
+
+func newConstraintLen(sign int) newConstraintFunc {
+	return func(f Field, elemType reflect.Type, value string) (func(v reflect.Value) error, error) {
+		switch f.Value.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+		default:
+			return nil, fmt.Errorf("%s has no length", f.Value.Type())
+		}
+
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+
+		op := ">="
+		if sign < 0 {
+			op = "<="
+		}
+
+		return func(v reflect.Value) error {
+			n := v.Len()
+			if v.Kind() == reflect.String {
+				n = utf8.RuneCountInString(v.String())
+			}
+
+			if cmp.Compare(n, limit)*sign < 0 {
+				return fmt.Errorf("length %d must be %s %d", n, op, limit)
+			}
+			return nil
+		}, nil
+	}
+}
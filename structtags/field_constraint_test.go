@@ -0,0 +1,111 @@
+package structtags
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConstraintStruct struct {
+	Port    int               `clic:"port" min:"1" max:"65535"`
+	Ratio   float64           `clic:"ratio" min:"0" max:"1"`
+	Timeout time.Duration     `clic:"timeout" max:"1m"`
+	Level   string            `clic:"level" enum:"debug,info,warn"`
+	Name    string            `clic:"name" pattern:"^[a-z]+$" minlen:"2" maxlen:"4"`
+	Hosts   []string          `clic:"hosts" pattern:"^h" minlen:"1"`
+	Ports   []uint            `clic:"ports" max:"100"`
+	Limits  map[string]int    `clic:"limits" min:"0" maxlen:"1"`
+	Labels  map[string]string `clic:"labels"`
+}
+
+func TestFieldValidate(t *testing.T) {
+	tests := []struct {
+		field  string
+		input  string
+		wantOk bool
+	}{
+		{"port", "1", true},
+		{"port", "65535", true},
+		{"port", "0", false},
+		{"port", "65536", false},
+		{"ratio", "0.5", true},
+		{"ratio", "1.5", false},
+		{"timeout", "1m", true},
+		{"timeout", "1m1s", false},
+		{"level", "info", true},
+		{"level", "error", false},
+		{"name", "ab", true},
+		{"name", "abcd", true},
+		{"name", "a", false},
+		{"name", "abcde", false},
+		{"name", "AB", false},
+		{"hosts", "h1,h2", true},
+		{"hosts", "h1,x2", false},
+		{"hosts", "", false},
+		{"ports", "1,100", true},
+		{"ports", "1,101", false},
+		{"limits", "a=1", true},
+		{"limits", "a=-1", false},
+		{"limits", "a=1,b=2", false},
+		{"labels", "a=1", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.field+"="+tc.input, func(t *testing.T) {
+			var value testConstraintStruct
+			fields, err := ParseStruct(reflect.ValueOf(&value), nil)
+			if err != nil {
+				t.Fatalf("ParseStruct(%T) returns an error: %v, want no error", value, err)
+			}
+
+			for _, field := range fields {
+				if field.Name[0] != tc.field {
+					continue
+				}
+
+				if err := field.UnmarshalText([]byte(tc.input)); err != nil {
+					t.Fatalf("Field %v: UnmarshalText(%q) returns an error: %v", field.Name, tc.input, err)
+				}
+
+				err := field.Validate()
+				if gotOk := err == nil; gotOk != tc.wantOk {
+					t.Errorf("Field %v: Validate() = %v, want ok: %v", field.Name, err, tc.wantOk)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidConstraint(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"MinString", &struct {
+			Str string `min:"1"`
+		}{}},
+		{"MaxInvalid", &struct {
+			Int int `max:"abc"`
+		}{}},
+		{"EnumInvalid", &struct {
+			Int int `enum:"1,b"`
+		}{}},
+		{"PatternInvalid", &struct {
+			Str string `pattern:"("`
+		}{}},
+		{"MinLenInt", &struct {
+			Int int `minlen:"1"`
+		}{}},
+		{"MaxLenInvalid", &struct {
+			Str string `maxlen:"abc"`
+		}{}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseStruct(reflect.ValueOf(tc.value), nil); err == nil {
+				t.Errorf("ParseStruct(%T) returns no error, want an error", tc.value)
+			}
+		})
+	}
+}
@@ -29,6 +29,81 @@ func getParseFieldFunc(t reflect.Type) ParseFieldFunc {
 	return parsers[t]
 }
 
+// getParseSliceElemFunc returns the parser of elements if `t` is a slice type, or nil if not.
+func getParseSliceElemFunc(t reflect.Type) ParseFieldFunc {
+	if t.Kind() != reflect.Slice {
+		return nil
+	}
+
+	return getParseFieldFunc(t.Elem())
+}
+
+func newParseFieldSlice(elemParser ParseFieldFunc, sep string) ParseFieldFunc {
+	return func(v reflect.Value, str string) error {
+		ret := reflect.MakeSlice(v.Type(), 0, 0)
+		if str == "" {
+			v.Set(ret)
+			return nil
+		}
+
+		for _, elemStr := range strings.Split(str, sep) {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := elemParser(elem, elemStr); err != nil {
+				return err
+			}
+			ret = reflect.Append(ret, elem)
+		}
+
+		v.Set(ret)
+		return nil
+	}
+}
+
+// getParseMapFuncs returns parsers of keys and elements if `t` is a map type, or nil if not.
+func getParseMapFuncs(t reflect.Type) (key, elem ParseFieldFunc) {
+	if t.Kind() != reflect.Map {
+		return nil, nil
+	}
+
+	return getParseFieldFunc(t.Key()), getParseFieldFunc(t.Elem())
+}
+
+func newParseFieldMap(keyParser, elemParser ParseFieldFunc, sep string) ParseFieldFunc {
+	return func(v reflect.Value, str string) error {
+		ret := reflect.MakeMap(v.Type())
+		if str != "" {
+			for _, pair := range strings.Split(str, sep) {
+				key, elem, ok := strings.Cut(pair, "=")
+				if !ok {
+					return fmt.Errorf("can't parse %q to a key=value pair", pair)
+				}
+
+				if err := setMapIndex(ret, keyParser, elemParser, key, elem); err != nil {
+					return err
+				}
+			}
+		}
+
+		v.Set(ret)
+		return nil
+	}
+}
+
+func setMapIndex(m reflect.Value, keyParser, elemParser ParseFieldFunc, keyStr, elemStr string) error {
+	key := reflect.New(m.Type().Key()).Elem()
+	if err := keyParser(key, keyStr); err != nil {
+		return err
+	}
+
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if err := elemParser(elem, elemStr); err != nil {
+		return err
+	}
+
+	m.SetMapIndex(key, elem)
+	return nil
+}
+
 var parsers = map[reflect.Type]ParseFieldFunc{
 	reflect.TypeOf(""):          parseFieldString,
 	reflect.TypeOf(int(0)):      parseFieldInt[int],
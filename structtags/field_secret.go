@@ -0,0 +1,80 @@
+package structtags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SecretMask replaces the value of a secret field when showing it.
+const SecretMask = "******"
+
+// RedactedText returns the text of the value like [Field.MarshalText], or [SecretMask] if the field is a secret and the value is not empty.
+func (f Field) RedactedText() ([]byte, error) {
+	buf, err := f.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Secret && len(buf) > 0 {
+		return []byte(SecretMask), nil
+	}
+
+	return buf, nil
+}
+
+// secretError is an error whose message has no secret value.
+// It matches errors in the chain of the original error with [errors.Is], like [strconv.ErrSyntax],
+// but doesn't unwrap to them, because their messages may contain the value.
+type secretError struct {
+	msg string
+	err error
+}
+
+func (e *secretError) Error() string {
+	return e.msg
+}
+
+func (e *secretError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// RedactError returns `err` as it is for a non-secret field.
+// For a secret field, it returns an error without the value, because an error of a parser may contain the value in any form, like quoted or as a number.
+func (f Field) RedactError(err error) error {
+	if err == nil || !f.Secret {
+		return err
+	}
+
+	if _, ok := err.(*secretError); ok {
+		return err
+	}
+
+	return &secretError{
+		msg: fmt.Sprintf("can't parse the secret value as %s", f.valueType()),
+		err: err,
+	}
+}
+
+// valueType returns the type of one value in the field, which is the element type for a slice or map field.
+func (f Field) valueType() reflect.Type {
+	if f.ElemParser != nil {
+		return f.Value.Type().Elem()
+	}
+
+	return f.Value.Type()
+}
+
+// validateSecret checks the value of a secret field with all constraints, without the value in the error.
+func (f Field) validateSecret() error {
+	for _, c := range f.Constraints {
+		if err := c.Check(f.Value); err != nil {
+			return &secretError{
+				msg: fmt.Sprintf("the secret value doesn't meet the constraint %s:%q", c.Name, c.Value),
+				err: err,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package structtags
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testSecretStruct struct {
+	Pin    int            `clic:"pin" secret:"true" min:"1000"`
+	Pins   []int          `clic:"pins" secret:"true"`
+	Tokens map[string]int `clic:"tokens" secret:"true"`
+	Empty  string         `clic:"empty" secret:"true"`
+}
+
+func TestFieldSecret(t *testing.T) {
+	var v testSecretStruct
+	fields, err := ParseStruct(reflect.ValueOf(&v), []string{"s"})
+	if err != nil {
+		t.Fatalf("ParseStruct() error: %v", err)
+	}
+	pin, pins, tokens, empty := fields[0], fields[1], fields[2], fields[3]
+
+	tests := []struct {
+		name   string
+		secret string
+		call   func() error
+	}{
+		{"UnmarshalText", "p4ss", func() error { return pin.UnmarshalText([]byte("p4ss")) }},
+		{"AppendText", "p4ss", func() error { return pins.AppendText([]byte("p4ss")) }},
+		{"AppendTextPair", "p4ss", func() error { return tokens.AppendText([]byte("p4ss")) }},
+		{"SetIndexText", "p4ss", func() error { return tokens.SetIndexText([]byte("key"), []byte("p4ss")) }},
+		{"Quoted", "p\"4ss", func() error { return pin.UnmarshalText([]byte("p\"4ss")) }},
+		{"Validate", "999", func() error {
+			if err := pin.UnmarshalText([]byte("999")); err != nil {
+				return err
+			}
+			return pin.Validate()
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if err == nil {
+				t.Fatalf("%s() returns no error, want an error", tc.name)
+			}
+
+			for e := err; e != nil; e = errors.Unwrap(e) {
+				msg := e.Error()
+				if strings.Contains(msg, tc.secret) || strings.Contains(msg, strconv.Quote(tc.secret)) {
+					t.Errorf("error %q in the chain of %q contains the secret %q", msg, err, tc.secret)
+				}
+			}
+		})
+	}
+
+	if err := pin.UnmarshalText([]byte("e")); err == nil || err.Error() != "can't parse the secret value as int" {
+		t.Errorf("pin.UnmarshalText(e) = %v, want: %q", err, "can't parse the secret value as int")
+	}
+	if err := pins.AppendText([]byte("e")); err == nil || err.Error() != "can't parse the secret value as int" {
+		t.Errorf("pins.AppendText(e) = %v, want: %q", err, "can't parse the secret value as int")
+	}
+
+	if err := pin.UnmarshalText([]byte("abc")); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("pin.UnmarshalText(abc) = %v, want wrapping: %v", err, strconv.ErrSyntax)
+	}
+
+	if err := pin.UnmarshalText([]byte("1234")); err != nil {
+		t.Fatalf("pin.UnmarshalText(1234) error: %v", err)
+	}
+	if got, _ := pin.RedactedText(); string(got) != SecretMask {
+		t.Errorf("pin.RedactedText() = %q, want: %q", got, SecretMask)
+	}
+	if got, _ := empty.RedactedText(); string(got) != "" {
+		t.Errorf("empty.RedactedText() = %q, want: %q", got, "")
+	}
+}
+
+type testSecretDefaultStruct struct {
+	Pin int `clic:"pin,p4ss" secret:"true"`
+}
+
+func TestFieldSecretDefault(t *testing.T) {
+	var v testSecretDefaultStruct
+	_, err := ParseStruct(reflect.ValueOf(&v), []string{"s"})
+	if err == nil {
+		t.Fatalf("ParseStruct() returns no error, want an error")
+	}
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if msg := e.Error(); strings.Contains(msg, "p4ss") {
+			t.Errorf("error %q in the chain of %q contains the secret %q", msg, err, "p4ss")
+		}
+	}
+}
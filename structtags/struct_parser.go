@@ -1,25 +1,171 @@
+// Package structtags parses fields of config structs with tags.
+//
+// A field could be a string, a bool, an integer, a float, a [time.Duration], a type implementing [encoding.TextUnmarshaler] or [flag.Value],
+// a slice or a map of them, or a struct of such fields. Arrays are not supported, and should be slices instead.
 package structtags
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+// Origin describes where the value of a field comes from.
+type Origin struct {
+	Source string // Source is the kind of the source, like "default", "flag", "env" or "file". It's empty if no one sets the field.
+	Key    string // Key is the key of the field in the source, like the flag name, the env key or the path in the config file.
+	File   string // File is the path of the config file which sets the field, or empty for other sources.
+	Line   int    // Line is the line of the field in the config file, or 0 if the format doesn't tell.
+}
+
+func (o Origin) String() string {
+	ret := o.Source
+	if o.Key != "" {
+		ret += " " + o.Key
+	}
+
+	if o.File != "" {
+		ret += " in " + o.File
+		if o.Line > 0 {
+			ret += ":" + strconv.Itoa(o.Line)
+		}
+	}
+
+	return ret
+}
+
+// Field is a field parsed from a struct with tags:
+//
+//   - `clic:"name,default,description"`: the name, the default value and the description of the field. The description could contain ",".
+//   - `default:"a,b"` and `desc:"..."`: the default value and the description, which override the ones in the `clic` tag. The default value could contain ",".
+//   - `env:"DATABASE_URL,DB_URL"`: the env key, instead of the one generated from the name. Other keys after "," are aliases.
+//   - `envfile:"true"`: reads the value from the file in the env key with the "_FILE" suffix, like "DATABASE_PASSWORD_FILE".
+//   - `flag:"database-url,db-url"`: the flag name, instead of the one generated from the name. Other names after "," are aliases.
+//   - `short:"d"`: the short alias of the flag.
+//   - `deprecated:"use -database.url instead"`: marks the field as deprecated, with a message.
+//   - `secret:"true"`: marks the value of the field as a secret, which is replaced with [SecretMask] in the usage and dumps, and is left out of errors.
+//   - `required:"true"`: the field must be set by a source or have a default value.
+//   - `sep:";"`: the separator of elements in a slice or map field, "," by default, like `a,b` or `alice=10,bob=20` in env and default values.
+//     A flag of a slice or map field could be repeated instead, like `-tenant.limits alice=10 -tenant.limits bob=20`, and a config file uses arrays and objects.
+//   - `arg:"1"`: binds the field to the positional argument at the position, starting from 1, instead of a flag, an env or a config file.
+//     A slice field takes all remaining arguments from the position.
+//     A positional argument is required unless it has a default value, including an empty one by `default:""`, or is a slice.
+//   - Constraints, like `min:"1"`. See [Constraint].
 type Field struct {
 	Name          []string
 	DefaultString string
+	HasDefault    bool // HasDefault is true if the field declares a default value, including an empty one by the `default` tag.
 	Description   string
+	Env           []string // Env is the env key and its aliases, or empty to use the key generated from the name.
+	EnvFile       bool     // EnvFile reads the value from the file in the env key with the "_FILE" suffix.
+	Flag          []string // Flag is the flag name and its aliases, or empty to use the name generated from the name.
+	Short         string
+	Deprecated    string
+	Secret        bool
+	Required      bool
+	Constraints   []Constraint
+	Separator     string
+	Arg           int // Arg is the position of the positional argument starting from 1, or 0 if the field is not a positional argument.
 	Parser        ParseFieldFunc
+	ElemParser    ParseFieldFunc // ElemParser parses one element of a slice or map field, or nil if the field is not a slice or map.
+	KeyParser     ParseFieldFunc // KeyParser parses one key of a map field, or nil if the field is not a map.
 	Value         reflect.Value
+
+	origin *Origin
+}
+
+// Origin returns where the current value of the field comes from.
+func (f Field) Origin() Origin {
+	if f.origin == nil {
+		return Origin{}
+	}
+
+	return *f.origin
+}
+
+// SetOrigin records where the current value of the field comes from. Sources should call it after setting the field.
+func (f Field) SetOrigin(origin Origin) {
+	if f.origin == nil {
+		return
+	}
+
+	*f.origin = origin
 }
 
 func (f Field) MarshalText() ([]byte, error) {
-	return fmt.Appendf(nil, "%v", f.Value.Interface()), nil
+	if f.ElemParser == nil {
+		return fmt.Appendf(nil, "%v", f.Value.Interface()), nil
+	}
+
+	var elems []string
+	if f.KeyParser == nil {
+		for i := range f.Value.Len() {
+			elems = append(elems, fmt.Sprintf("%v", f.Value.Index(i).Interface()))
+		}
+	} else {
+		iter := f.Value.MapRange()
+		for iter.Next() {
+			elems = append(elems, fmt.Sprintf("%v=%v", iter.Key().Interface(), iter.Value().Interface()))
+		}
+		slices.Sort(elems)
+	}
+
+	return []byte(strings.Join(elems, f.Separator)), nil
 }
 
 func (f Field) UnmarshalText(buf []byte) error {
-	return f.Parser(f.Value, string(buf))
+	return f.RedactError(f.Parser(f.Value, string(buf)))
+}
+
+// IsVariadic returns true if the field is a positional argument which takes all remaining arguments.
+func (f Field) IsVariadic() bool {
+	return f.Arg > 0 && f.ElemParser != nil
+}
+
+// AppendText parses `buf` as one element and appends it to a slice field, or parses `buf` as one "key=value" pair and sets it to a map field.
+// For other fields, it's same as [Field.UnmarshalText].
+func (f Field) AppendText(buf []byte) error {
+	if f.ElemParser == nil {
+		return f.UnmarshalText(buf)
+	}
+
+	if f.KeyParser != nil {
+		key, value, ok := strings.Cut(string(buf), "=")
+		if !ok {
+			return f.RedactError(fmt.Errorf("can't parse %q to a key=value pair", buf))
+		}
+		return f.SetIndexText([]byte(key), []byte(value))
+	}
+
+	elem := reflect.New(f.Value.Type().Elem()).Elem()
+	if err := f.ElemParser(elem, string(buf)); err != nil {
+		return f.RedactError(err)
+	}
+
+	f.Value.Set(reflect.Append(f.Value, elem))
+	return nil
+}
+
+// SetIndexText parses `key` and `value`, and sets the pair to a map field.
+func (f Field) SetIndexText(key, value []byte) error {
+	if f.KeyParser == nil {
+		return fmt.Errorf("field %v is not a map", f.Name)
+	}
+
+	if f.Value.IsNil() {
+		f.Value.Set(reflect.MakeMap(f.Value.Type()))
+	}
+
+	err := setMapIndex(f.Value, f.KeyParser, f.ElemParser, string(key), string(value))
+	return f.RedactError(err)
+}
+
+// Clear sets the field to the zero value.
+func (f Field) Clear() {
+	f.Value.SetZero()
 }
 
 func ParseStruct(v reflect.Value, name []string) ([]Field, error) {
@@ -37,11 +183,15 @@ func ParseStruct(v reflect.Value, name []string) ([]Field, error) {
 	vfields := reflect.VisibleFields(t)
 
 	for _, vfield := range vfields {
-		if vfield.Anonymous {
+		// Unexported fields are left alone, and exported fields of embedded structs are promoted.
+		if vfield.Anonymous || !vfield.IsExported() {
 			continue
 		}
 
-		f := getFieldTag(vfield, name)
+		f, err := getFieldTag(vfield, name)
+		if err != nil {
+			return nil, err
+		}
 
 		vfieldValue := v.FieldByIndex(vfield.Index)
 		vfieldType := vfieldValue.Type()
@@ -58,11 +208,40 @@ func ParseStruct(v reflect.Value, name []string) ([]Field, error) {
 
 		if parser := getParseFieldFunc(vfieldType); parser != nil {
 			f.Parser = parser
+		} else if elemParser := getParseSliceElemFunc(vfieldType); elemParser != nil {
+			f.Separator = cmp.Or(vfield.Tag.Get("sep"), ",")
+			f.Parser = newParseFieldSlice(elemParser, f.Separator)
+			f.ElemParser = elemParser
+		} else if keyParser, elemParser := getParseMapFuncs(vfieldType); keyParser != nil && elemParser != nil {
+			f.Separator = cmp.Or(vfield.Tag.Get("sep"), ",")
+			f.Parser = newParseFieldMap(keyParser, elemParser, f.Separator)
+			f.ElemParser = elemParser
+			f.KeyParser = keyParser
+		}
+
+		if f.Parser == nil && vfieldType.Kind() == reflect.Array {
+			return nil, fmt.Errorf("array field %v is not supported, use a slice instead", f.Name)
+		}
+
+		if f.Arg > 0 && f.KeyParser != nil {
+			return nil, fmt.Errorf("map field %v can't be a positional argument", f.Name)
+		}
+
+		if f.Parser != nil {
 			f.Value = vfieldValue
+			f.origin = &Origin{}
+			if f.Constraints, err = getConstraints(vfield, f); err != nil {
+				return nil, err
+			}
 			if f.DefaultString != "" {
 				if err := f.Parser(f.Value, f.DefaultString); err != nil {
-					return nil, fmt.Errorf("can't parse default value %q for field %v: %w", f.DefaultString, f.Name, err)
+					value := strconv.Quote(f.DefaultString)
+					if f.Secret {
+						value = SecretMask
+					}
+					return nil, fmt.Errorf("can't parse default value %s for field %v: %w", value, f.Name, f.RedactError(err))
 				}
+				f.SetOrigin(Origin{Source: "default"})
 			}
 			ret = append(ret, f)
 			continue
@@ -78,31 +257,84 @@ func ParseStruct(v reflect.Value, name []string) ([]Field, error) {
 	return ret, nil
 }
 
-func getFieldTag(sfield reflect.StructField, name []string) (ret Field) {
+func getFieldTag(sfield reflect.StructField, name []string) (ret Field, err error) {
 	tagStr := sfield.Tag.Get("clic")
 	tagArray := strings.SplitN(tagStr, ",", 3)
-	name = name[:]
+	name = slices.Clip(name)
 
-	switch len(tagArray) {
-	case 3:
+	switch {
+	case tagStr != "":
 		ret.Name = append(name, tagArray[0])
+	case sfield.Name != "":
+		ret.Name = append(name, sfield.Name)
+	}
+
+	if len(tagArray) > 1 {
 		ret.DefaultString = tagArray[1]
+	}
+
+	if len(tagArray) > 2 {
 		ret.Description = tagArray[2]
-		return
-	case 2:
-		ret.Name = append(name, tagArray[0])
-		ret.DefaultString = tagArray[1]
-		return
-	case 1:
-		if tagStr != "" {
-			ret.Name = append(name, tagArray[0])
-			return
+	}
+
+	if value, ok := sfield.Tag.Lookup("default"); ok {
+		ret.DefaultString = value
+		ret.HasDefault = true
+	}
+	ret.HasDefault = ret.HasDefault || ret.DefaultString != ""
+
+	if value, ok := sfield.Tag.Lookup("desc"); ok {
+		ret.Description = value
+	}
+
+	ret.Env = getListTag(sfield, "env")
+	ret.Flag = getListTag(sfield, "flag")
+	ret.Short = sfield.Tag.Get("short")
+	ret.Deprecated = sfield.Tag.Get("deprecated")
+
+	if ret.Required, err = getBoolTag(sfield, "required", ret.Name); err != nil {
+		return ret, err
+	}
+
+	if ret.Secret, err = getBoolTag(sfield, "secret", ret.Name); err != nil {
+		return ret, err
+	}
+
+	if ret.EnvFile, err = getBoolTag(sfield, "envfile", ret.Name); err != nil {
+		return ret, err
+	}
+
+	if value := sfield.Tag.Get("arg"); value != "" {
+		if ret.Arg, err = strconv.Atoi(value); err != nil || ret.Arg < 1 {
+			return ret, fmt.Errorf("invalid arg tag %q for field %v: must be a position starting from 1", value, ret.Name)
 		}
 	}
 
-	if sfield.Name != "" {
-		ret.Name = append(name, sfield.Name)
+	return ret, nil
+}
+
+func getBoolTag(sfield reflect.StructField, key string, name []string) (bool, error) {
+	value := sfield.Tag.Get(key)
+	if value == "" {
+		return false, nil
+	}
+
+	ret, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s tag %q for field %v: %w", key, value, name, err)
+	}
+
+	return ret, nil
+}
+
+// getListTag returns values separated by "," in the tag with `key`, ignoring empty ones.
+func getListTag(sfield reflect.StructField, key string) []string {
+	var ret []string
+	for value := range strings.SplitSeq(sfield.Tag.Get(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			ret = append(ret, value)
+		}
 	}
 
-	return
+	return ret
 }
@@ -10,6 +10,10 @@ type TestWithChan struct {
 	Chan chan int
 }
 
+type TestWithArray struct {
+	Array [3]int `clic:"array"`
+}
+
 type TestLayerWithChan struct {
 	Inner TestWithChan `clic:"inner"`
 }
@@ -17,12 +21,14 @@ type TestLayerWithChan struct {
 func TestStructParseInvalid(t *testing.T) {
 	var chanValue TestWithChan
 	var layerValue TestLayerWithChan
+	var arrayValue TestWithArray
 
 	tests := []struct {
 		value any
 	}{
 		{&chanValue},
 		{&layerValue},
+		{&arrayValue},
 	}
 
 	for _, tc := range tests {
@@ -36,3 +42,32 @@ func TestStructParseInvalid(t *testing.T) {
 		})
 	}
 }
+
+type TestInvalidRequired struct {
+	Int int `clic:"int" required:"yes"`
+}
+
+type TestInvalidSecret struct {
+	Str string `clic:"str" secret:"yes"`
+}
+
+type TestInvalidArg struct {
+	Str string `clic:"str" arg:"0"`
+}
+
+type TestInvalidMapArg struct {
+	Map map[string]string `clic:"map" arg:"1"`
+}
+
+func TestStructParseInvalidTag(t *testing.T) {
+	var required TestInvalidRequired
+	var secret TestInvalidSecret
+	var arg TestInvalidArg
+	var mapArg TestInvalidMapArg
+
+	for _, value := range []any{&required, &secret, &arg, &mapArg} {
+		if _, err := ParseStruct(reflect.ValueOf(value), []string{"test"}); err == nil {
+			t.Errorf("ParseStruct(%T, ['test']) should returns an error, but not", value)
+		}
+	}
+}
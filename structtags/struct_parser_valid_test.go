@@ -3,6 +3,7 @@ package structtags
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -23,21 +24,25 @@ var testStructFields = []Field{
 	{
 		Name:          []string{"test", "int"},
 		DefaultString: "10",
+		HasDefault:    true,
 		Description:   "integer value",
 	},
 	{
 		Name:          []string{"test", "pint"},
 		DefaultString: "20",
+		HasDefault:    true,
 		Description:   "pointer to integer",
 	},
 	{
 		Name:          []string{"test", "str"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "string value",
 	},
 	{
 		Name:          []string{"test", "pstr"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "pointer to string",
 	},
 }
@@ -52,6 +57,7 @@ var testPartClicFields = []Field{
 	{
 		Name:          []string{"test", "no_desc"},
 		DefaultString: "default",
+		HasDefault:    true,
 		Description:   "",
 	},
 	{
@@ -77,95 +83,186 @@ var testLayerStructFields = []Field{
 	{
 		Name:          []string{"test", "struct", "int"},
 		DefaultString: "10",
+		HasDefault:    true,
 		Description:   "integer value",
 	},
 	{
 		Name:          []string{"test", "struct", "pint"},
 		DefaultString: "20",
+		HasDefault:    true,
 		Description:   "pointer to integer",
 	},
 	{
 		Name:          []string{"test", "struct", "str"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "string value",
 	},
 	{
 		Name:          []string{"test", "struct", "pstr"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "pointer to string",
 	},
 	{
 		Name:          []string{"test", "pstruct", "int"},
 		DefaultString: "10",
+		HasDefault:    true,
 		Description:   "integer value",
 	},
 	{
 		Name:          []string{"test", "pstruct", "pint"},
 		DefaultString: "20",
+		HasDefault:    true,
 		Description:   "pointer to integer",
 	},
 	{
 		Name:          []string{"test", "pstruct", "str"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "string value",
 	},
 	{
 		Name:          []string{"test", "pstruct", "pstr"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "pointer to string",
 	},
 	{
 		Name:          []string{"test", "embed", "int"},
 		DefaultString: "10",
+		HasDefault:    true,
 		Description:   "integer value",
 	},
 	{
 		Name:          []string{"test", "embed", "pint"},
 		DefaultString: "20",
+		HasDefault:    true,
 		Description:   "pointer to integer",
 	},
 	{
 		Name:          []string{"test", "embed", "str"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "string value",
 	},
 	{
 		Name:          []string{"test", "embed", "pstr"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "pointer to string",
 	},
 	{
 		Name:          []string{"test", "pembed", "int"},
 		DefaultString: "10",
+		HasDefault:    true,
 		Description:   "integer value",
 	},
 	{
 		Name:          []string{"test", "pembed", "pint"},
 		DefaultString: "20",
+		HasDefault:    true,
 		Description:   "pointer to integer",
 	},
 	{
 		Name:          []string{"test", "pembed", "str"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "string value",
 	},
 	{
 		Name:          []string{"test", "pembed", "pstr"},
 		DefaultString: "some str",
+		HasDefault:    true,
 		Description:   "pointer to string",
 	},
 }
 
+type TestDeepStruct struct {
+	L1 struct {
+		L2 struct {
+			A string `clic:"a"`
+			B string `clic:"b,default" required:"true"`
+		} `clic:"l2"`
+	} `clic:"l1"`
+}
+
+var testDeepStructFields = []Field{
+	{
+		Name: []string{"test", "l1", "l2", "a"},
+	},
+	{
+		Name:          []string{"test", "l1", "l2", "b"},
+		DefaultString: "default",
+		HasDefault:    true,
+		Required:      true,
+	},
+}
+
+type TestTagKeys struct {
+	Hosts    []string `clic:"hosts,a" default:"b,c" desc:"hosts, separated by comma"`
+	URL      string   `clic:"url,,the url" env:"DATABASE_URL, DB_URL" flag:"db-url" short:"u"`
+	Password string   `clic:"password" secret:"true" envfile:"true" deprecated:"use url instead"`
+	Src      string   `clic:"src" arg:"1"`
+	Dst      []string `clic:"dst" arg:"2"`
+	Mode     string   `clic:"mode" default:""`
+
+	cache map[string]string // unexported fields are not parsed.
+}
+
+var testTagKeysFields = []Field{
+	{
+		Name:          []string{"test", "hosts"},
+		DefaultString: "b,c",
+		HasDefault:    true,
+		Description:   "hosts, separated by comma",
+	},
+	{
+		Name:        []string{"test", "url"},
+		Description: "the url",
+		Env:         []string{"DATABASE_URL", "DB_URL"},
+		Flag:        []string{"db-url"},
+		Short:       "u",
+	},
+	{
+		Name:       []string{"test", "password"},
+		Secret:     true,
+		EnvFile:    true,
+		Deprecated: "use url instead",
+	},
+	{
+		Name: []string{"test", "src"},
+		Arg:  1,
+	},
+	{
+		Name: []string{"test", "dst"},
+		Arg:  2,
+	},
+	{
+		Name:       []string{"test", "mode"},
+		HasDefault: true,
+	},
+}
+
 func compareField(x, y Field) bool {
 	if !reflect.DeepEqual(x.Name, y.Name) {
 		return false
 	}
-	if x.DefaultString != y.DefaultString {
+	if x.DefaultString != y.DefaultString || x.HasDefault != y.HasDefault {
 		return false
 	}
 	if x.Description != y.Description {
 		return false
 	}
+	if x.Required != y.Required {
+		return false
+	}
+	if !slices.Equal(x.Env, y.Env) || !slices.Equal(x.Flag, y.Flag) || x.EnvFile != y.EnvFile {
+		return false
+	}
+	if x.Short != y.Short || x.Deprecated != y.Deprecated || x.Secret != y.Secret || x.Arg != y.Arg {
+		return false
+	}
 	return true
 }
 
@@ -174,6 +271,8 @@ func TestParseStruct(t *testing.T) {
 	var structValue TestStruct
 	var embedValue TestEmbedStruct
 	var layerValue TestLayersStruct
+	var deepValue TestDeepStruct
+	var tagKeysValue TestTagKeys
 
 	tests := []struct {
 		value      any
@@ -183,6 +282,8 @@ func TestParseStruct(t *testing.T) {
 		{&structValue, testStructFields},
 		{&embedValue, testStructFields},
 		{&layerValue, testLayerStructFields},
+		{&deepValue, testDeepStructFields},
+		{&tagKeysValue, testTagKeysFields},
 	}
 
 	for _, tc := range tests {
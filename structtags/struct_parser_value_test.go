@@ -24,6 +24,14 @@ func TestParseStructValue(t *testing.T) {
 		t.Fatalf("ParseStruct(%T) returns an error: %v, want no error", value, err)
 	}
 
+	t.Run("DefaultOrigin", func(t *testing.T) {
+		for _, field := range fields {
+			if got, want := field.Origin(), (Origin{Source: "default"}); got != want {
+				t.Errorf("Field %v: Origin() = %v, want: %v", field.Name, got, want)
+			}
+		}
+	})
+
 	t.Run("DefaultValue", func(t *testing.T) {
 		i := 20
 		wantDefault := testValueStruct{
@@ -84,3 +92,165 @@ func TestParseStructInvalidValue(t *testing.T) {
 		t.Fatalf("ParseStruct(%T) returns no error, want an parsing error", value)
 	}
 }
+
+type testSliceStruct struct {
+	Strs []string        `clic:"strs,a;b" sep:";"`
+	Ints []int           `clic:"ints"`
+	Durs []time.Duration `clic:"durs,1s"`
+}
+
+func TestParseStructSlice(t *testing.T) {
+	var value testSliceStruct
+	fields, err := ParseStruct(reflect.ValueOf(&value), []string{"test"})
+	if err != nil {
+		t.Fatalf("ParseStruct(%T) returns an error: %v, want no error", value, err)
+	}
+
+	t.Run("DefaultValue", func(t *testing.T) {
+		want := testSliceStruct{
+			Strs: []string{"a", "b"},
+			Durs: []time.Duration{time.Second},
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+	})
+
+	t.Run("UnmarshalText", func(t *testing.T) {
+		fieldStrings := []string{"x;y;z", "1,2", ""}
+		want := testSliceStruct{
+			Strs: []string{"x", "y", "z"},
+			Ints: []int{1, 2},
+			Durs: []time.Duration{},
+		}
+
+		for i, fieldString := range fieldStrings {
+			if err := fields[i].UnmarshalText([]byte(fieldString)); err != nil {
+				t.Fatalf("Field %v: UnmarshalText(%q) returns an error: %v, want no error", fields[i].Name, fieldString, err)
+			}
+
+			gotBuf, err := fields[i].MarshalText()
+			if err != nil {
+				t.Fatalf("Field %v: MarshalText() returns %v, want no error", fields[i].Name, err)
+			}
+
+			if got, want := string(gotBuf), fieldString; got != want {
+				t.Errorf("Field %v: MarshalText() = %q, want: %q", fields[i].Name, got, want)
+			}
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+	})
+
+	t.Run("AppendText", func(t *testing.T) {
+		for _, field := range fields {
+			field.Clear()
+		}
+
+		fieldStrings := [][]string{{"a,b", "c"}, {"1", "2"}, {"1h", "1m"}}
+		want := testSliceStruct{
+			Strs: []string{"a,b", "c"},
+			Ints: []int{1, 2},
+			Durs: []time.Duration{time.Hour, time.Minute},
+		}
+
+		for i, strs := range fieldStrings {
+			for _, str := range strs {
+				if err := fields[i].AppendText([]byte(str)); err != nil {
+					t.Fatalf("Field %v: AppendText(%q) returns an error: %v, want no error", fields[i].Name, str, err)
+				}
+			}
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+
+		if err := fields[1].AppendText([]byte("abc")); err == nil {
+			t.Errorf("Field %v: AppendText(%q) returns no error, want an error", fields[1].Name, "abc")
+		}
+	})
+}
+
+type testMapStruct struct {
+	Labels map[string]string `clic:"labels,a=1;b=2" sep:";"`
+	Limits map[string]int    `clic:"limits"`
+}
+
+func TestParseStructMap(t *testing.T) {
+	var value testMapStruct
+	fields, err := ParseStruct(reflect.ValueOf(&value), []string{"test"})
+	if err != nil {
+		t.Fatalf("ParseStruct(%T) returns an error: %v, want no error", value, err)
+	}
+
+	t.Run("DefaultValue", func(t *testing.T) {
+		want := testMapStruct{
+			Labels: map[string]string{"a": "1", "b": "2"},
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+	})
+
+	t.Run("UnmarshalText", func(t *testing.T) {
+		fieldStrings := []string{"x=1;y=a=b", "a=1,b=2"}
+		want := testMapStruct{
+			Labels: map[string]string{"x": "1", "y": "a=b"},
+			Limits: map[string]int{"a": 1, "b": 2},
+		}
+
+		for i, fieldString := range fieldStrings {
+			if err := fields[i].UnmarshalText([]byte(fieldString)); err != nil {
+				t.Fatalf("Field %v: UnmarshalText(%q) returns an error: %v, want no error", fields[i].Name, fieldString, err)
+			}
+
+			gotBuf, err := fields[i].MarshalText()
+			if err != nil {
+				t.Fatalf("Field %v: MarshalText() returns %v, want no error", fields[i].Name, err)
+			}
+
+			if got, want := string(gotBuf), fieldString; got != want {
+				t.Errorf("Field %v: MarshalText() = %q, want: %q", fields[i].Name, got, want)
+			}
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+	})
+
+	t.Run("AppendText", func(t *testing.T) {
+		for _, field := range fields {
+			field.Clear()
+		}
+
+		fieldStrings := [][]string{{"a=1,2", "b="}, {"a=1", "a=2"}}
+		want := testMapStruct{
+			Labels: map[string]string{"a": "1,2", "b": ""},
+			Limits: map[string]int{"a": 2},
+		}
+
+		for i, strs := range fieldStrings {
+			for _, str := range strs {
+				if err := fields[i].AppendText([]byte(str)); err != nil {
+					t.Fatalf("Field %v: AppendText(%q) returns an error: %v, want no error", fields[i].Name, str, err)
+				}
+			}
+		}
+
+		if diff := cmp.Diff(value, want); diff != "" {
+			t.Errorf("Diff: (-got, +want)\n%s", diff)
+		}
+
+		for _, str := range []string{"a", "a=b"} {
+			if err := fields[1].AppendText([]byte(str)); err == nil {
+				t.Errorf("Field %v: AppendText(%q) returns no error, want an error", fields[1].Name, str)
+			}
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package clic_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/googollee/clic"
+)
+
+func ExampleSet_tags() {
+	// prepare env
+	if err := os.Setenv("DATABASE_URL", "postgres://localhost/db"); err != nil {
+		log.Fatal("set env error:", err)
+	}
+
+	// code starts
+	type Database struct {
+		URL      string   `clic:"url" env:"DATABASE_URL" desc:"the url of the database, like postgres://host/db"`
+		Replicas []string `clic:"replicas" default:"replica1,replica2" desc:"the hosts of replicas"`
+		Password string   `clic:"password" default:"admin" secret:"true" desc:"the password of the database"`
+		PoolSize int      `clic:"pool_size,10,the size of the connection pool" short:"p"`
+		MaxConns int      `clic:"max_conns,10,the max connections" deprecated:"use -database.pool_size instead"`
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	var helpOutput bytes.Buffer
+	fset.SetOutput(&helpOutput)
+	set := clic.NewSet(fset)
+
+	var db Database
+	set.RegisterValue("database", &db)
+
+	ctx := context.Background()
+	if err := set.Parse(ctx, []string{"-p", "20"}); err != nil {
+		log.Fatal("parse error:", err)
+	}
+
+	fmt.Println("URL:", db.URL)
+	fmt.Println("Replicas:", db.Replicas)
+	fmt.Println("PoolSize:", db.PoolSize)
+
+	fset.PrintDefaults()
+	fmt.Println(strings.ReplaceAll(helpOutput.String(), "\t", "    "))
+
+	// Output:
+	// URL: postgres://localhost/db
+	// Replicas: [replica1 replica2]
+	// PoolSize: 20
+	//   -config value
+	//         the paths of config files, separated by ","
+	//   -database.max_conns value
+	//         the max connections (deprecated: use -database.pool_size instead) (default 10)
+	//   -database.password value
+	//         the password of the database (default ******)
+	//   -database.pool_size value
+	//         the size of the connection pool (default 10)
+	//   -database.replicas value
+	//         the hosts of replicas (default replica1,replica2)
+	//   -database.url value
+	//         the url of the database, like postgres://host/db
+	//   -p value
+	//         short for -database.pool_size (default 10)
+}
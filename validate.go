@@ -0,0 +1,114 @@
+package clic
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/googollee/clic/source"
+	"github.com/googollee/clic/structtags"
+)
+
+// ErrRequired means a required field is not set by any source.
+var ErrRequired = errors.New("required value is missing")
+
+// ValidationError contains all invalid fields found when parsing the configuration.
+type ValidationError struct {
+	Errors []*source.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d invalid config fields:", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	ret := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		ret = append(ret, err)
+	}
+
+	return ret
+}
+
+// fieldErrors returns all [*source.FieldError] in `err`, or false if `err` contains other errors.
+func fieldErrors(err error) ([]*source.FieldError, bool) {
+	if fieldErr, ok := err.(*source.FieldError); ok {
+		return []*source.FieldError{fieldErr}, true
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+
+	var ret []*source.FieldError
+	for _, err := range joined.Unwrap() {
+		errs, ok := fieldErrors(err)
+		if !ok {
+			return nil, false
+		}
+		ret = append(ret, errs...)
+	}
+
+	return ret, true
+}
+
+// validate checks all fields and returns a [*ValidationError] with `errs` and all invalid fields, or nil if all fields are valid.
+func (s *Set) validate(errs []*source.FieldError) error {
+	for _, field := range s.fields {
+		if slices.ContainsFunc(errs, func(err *source.FieldError) bool { return slices.Equal(err.Field, field.Name) }) {
+			continue
+		}
+
+		origin := field.Origin()
+		if origin.Source == "" {
+			if field.Required || (field.Arg > 0 && !isOptionalArg(field)) {
+				errs = append(errs, &source.FieldError{Field: field.Name, Err: ErrRequired})
+			}
+			continue
+		}
+
+		if err := field.Validate(); err != nil {
+			errs = append(errs, &source.FieldError{Field: field.Name, Origin: origin, Err: err})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, err := range errs {
+		err.Keys = s.fieldKeys(err.Field)
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+// fieldKeys returns where the field with `name` could be set in all sources.
+func (s *Set) fieldKeys(name []string) []structtags.Origin {
+	i := slices.IndexFunc(s.fields, func(field structtags.Field) bool { return slices.Equal(field.Name, name) })
+	if i < 0 {
+		return nil
+	}
+
+	if s.fields[i].Arg > 0 {
+		return []structtags.Origin{argOrigin(s.fields[i])}
+	}
+
+	var ret []structtags.Origin
+	for _, src := range s.sources {
+		if keyer, ok := src.(source.Keyer); ok {
+			ret = append(ret, keyer.Keys(s.fields[i])...)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,63 @@
+package clic_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/googollee/clic"
+)
+
+func ExampleSet_requiredFields() {
+	// prepare env
+	if err := os.Setenv("DATABASE_POOL_SIZE", "many"); err != nil {
+		fmt.Println("set env error:", err)
+		return
+	}
+
+	// code starts
+	type Database struct {
+		DSN      string `clic:"dsn,,the dsn of the database" required:"true"`
+		Driver   string `clic:"driver,sqlite3,the driver of the database" required:"true"`
+		PoolSize int    `clic:"pool_size,10,the size of the connection pool"`
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	set := clic.NewSet(fset)
+
+	var db Database
+	set.RegisterValue("database", &db)
+
+	ctx := context.Background()
+	err := set.Parse(ctx, []string{})
+	fmt.Println(err)
+
+	// Output:
+	// 2 invalid config fields:
+	//   database.pool_size (flag -database.pool_size, file database.pool_size, env DATABASE_POOL_SIZE) from env DATABASE_POOL_SIZE: can't parse "many" to an integer: strconv.ParseInt: parsing "many": invalid syntax
+	//   database.dsn (flag -database.dsn, file database.dsn, env DATABASE_DSN): required value is missing
+}
+
+func ExampleSet_constraints() {
+	type Server struct {
+		Port     int    `clic:"port,8080,the port to listen" min:"1" max:"65535"`
+		LogLevel string `clic:"log_level,info,the level of the log" enum:"debug,info,warn,error"`
+		Name     string `clic:"name,server,the name of the server" pattern:"^[a-z]+$" maxlen:"16"`
+	}
+
+	fset := flag.NewFlagSet("", flag.ContinueOnError)
+	set := clic.NewSet(fset)
+
+	var server Server
+	set.RegisterValue("server", &server)
+
+	ctx := context.Background()
+	err := set.Parse(ctx, []string{"-server.port", "65536", "-server.log_level", "trace"})
+	fmt.Println(err)
+
+	// Output:
+	// 2 invalid config fields:
+	//   server.port (flag -server.port, file server.port, env SERVER_PORT) from flag -server.port: 65536 must be <= 65535
+	//   server.log_level (flag -server.log_level, file server.log_level, env SERVER_LOG_LEVEL) from flag -server.log_level: trace must be one of [debug,info,warn,error]
+}